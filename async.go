@@ -0,0 +1,211 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// An AsyncWriter queues writes and performs them in a background
+// goroutine, so logging does not wait for a slow disk or network share.
+// Write copies the data and returns once it is queued; when the queue is
+// full it blocks or, with SetNonBlocking, drops the data. Errors of
+// background writes are returned by later calls.
+//
+//	w := log.NewAsyncWriter(file, 4096)
+//	logger := log.New(w, log.LevelInfo, 0, nil)
+//	logger.Own(w)
+type AsyncWriter struct {
+	w    io.Writer
+	size int
+
+	mu      sync.Mutex
+	cond    sync.Cond // signaled when the queue or busy changes
+	queue   [][]byte
+	busy    bool // the writer goroutine is writing
+	closed  bool
+	err     error
+	spare   [][]byte // buffers for reuse
+	pending []byte   // the batch being written
+
+	nonBlocking bool
+	dropped     uint64    // total writes dropped
+	unreported  int       // dropped since the last report
+	reported    time.Time // time of the last report
+}
+
+// dropReportInterval is the least time between reports of drops.
+const dropReportInterval = time.Second
+
+// NewAsyncWriter creates a writer queuing up to size writes for w, 1024
+// if size is not positive.
+func NewAsyncWriter(w io.Writer, size int) *AsyncWriter {
+	if size <= 0 {
+		size = 1024
+	}
+	a := &AsyncWriter{w: w, size: size}
+	a.cond.L = &a.mu
+	go a.run()
+	return a
+}
+
+// Write queues a copy of p.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for len(a.queue) >= a.size && !a.closed {
+		if a.nonBlocking {
+			a.dropped++
+			a.unreported++
+			return len(p), nil
+		}
+		a.cond.Wait()
+	}
+	if a.closed {
+		return 0, ErrClosed
+	}
+	var b []byte
+	if n := len(a.spare); n > 0 {
+		b = a.spare[n-1][:0]
+		a.spare = a.spare[:n-1]
+	}
+	a.queue = append(a.queue, append(b, p...))
+	a.cond.Broadcast()
+	err := a.err
+	a.err = nil
+	return len(p), err
+}
+
+// SetNonBlocking makes writes to a full queue drop their data instead of
+// waiting, for logging from latency critical code. A logger writing to a
+// non-blocking AsyncWriter reports drops in a warning at most once a
+// second, as long as it keeps logging.
+func (a *AsyncWriter) SetNonBlocking(on bool) {
+	a.mu.Lock()
+	a.nonBlocking = on
+	a.mu.Unlock()
+}
+
+// Dropped returns the number of writes dropped because the queue was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
+
+// dropReport returns the number of drops to report at now, if any, and
+// counts them as reported.
+func (a *AsyncWriter) dropReport(now time.Time) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := a.unreported
+	if n == 0 || now.Sub(a.reported) < dropReportInterval || len(a.queue) >= a.size {
+		// Nothing to report, or the report would be dropped too.
+		return 0
+	}
+	a.unreported = 0
+	a.reported = now
+	return n
+}
+
+func (a *AsyncWriter) run() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for len(a.queue) == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if len(a.queue) == 0 {
+			return
+		}
+		// Write everything queued at once.
+		batch := a.pending[:0]
+		for _, b := range a.queue {
+			batch = append(batch, b...)
+			if len(a.spare) < a.size {
+				a.spare = append(a.spare, b)
+			}
+		}
+		a.queue = a.queue[:0]
+		a.busy = true
+		a.cond.Broadcast()
+		a.mu.Unlock()
+		_, err := a.w.Write(batch)
+		a.mu.Lock()
+		a.pending = batch
+		a.busy = false
+		if err != nil {
+			a.err = err
+		}
+		a.cond.Broadcast()
+	}
+}
+
+// drain waits until everything queued is written. Called with a.mu held.
+func (a *AsyncWriter) drain() {
+	for len(a.queue) > 0 || a.busy {
+		a.cond.Wait()
+	}
+}
+
+// Flush waits until the queued writes are done and flushes the underlying
+// writer if it buffers.
+func (a *AsyncWriter) Flush() error {
+	a.mu.Lock()
+	a.drain()
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+	if f, ok := a.w.(flusher); ok && err == nil {
+		err = f.Flush()
+	}
+	return err
+}
+
+// Sync is Flush followed by syncing the underlying writer, if it is a
+// file.
+func (a *AsyncWriter) Sync() error {
+	err := a.Flush()
+	if s, ok := a.w.(syncer); ok && err == nil {
+		err = s.Sync()
+	}
+	return err
+}
+
+// Reopen waits until the queued writes are done and reopens the underlying
+// writer, if it is a File or can be reopened otherwise.
+func (a *AsyncWriter) Reopen() error {
+	ro, ok := a.w.(reopener)
+	if !ok {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.drain()
+	// Holding the lock keeps the writer goroutine from starting a batch.
+	return ro.Reopen()
+}
+
+// Close writes what is queued, stops the background goroutine and closes
+// the underlying writer.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrClosed
+	}
+	a.drain()
+	a.closed = true
+	err := a.err
+	if a.unreported > 0 {
+		err = errors.Join(err, fmt.Errorf("log: %d writes dropped since the last report", a.unreported))
+	}
+	a.cond.Broadcast()
+	a.mu.Unlock()
+	if cerr := closeOutput(a.w); err == nil {
+		err = cerr
+	}
+	return err
+}
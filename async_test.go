@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter holds writes back while gate is locked and keeps what is
+// written.
+type gateWriter struct {
+	gate sync.Mutex
+	mu   sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.gate.Lock()
+	w.gate.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterDropReport(t *testing.T) {
+	gw := &gateWriter{}
+	a := NewAsyncWriter(gw, 4)
+	a.SetNonBlocking(true)
+	l := New(a, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	now := time.Now()
+	l.SetClock(func() time.Time { return now })
+	// flood logs more than the queue holds while the writer is stuck and
+	// returns the number of entries dropped.
+	flood := func() uint64 {
+		before := a.Dropped()
+		gw.gate.Lock()
+		for i := 0; i < 20; i++ {
+			l.Info("x")
+		}
+		gw.gate.Unlock()
+		if err := a.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		return a.Dropped() - before
+	}
+	report := func(n uint64) string {
+		return "WARN  log: dropped " + strconv.FormatUint(n, 10) + " entries dropped=" + strconv.FormatUint(n, 10) + "\n"
+	}
+	// lines logs msg and returns what it adds to the output.
+	lines := func(msg string) string {
+		before := len(gw.String())
+		l.Info(msg)
+		if err := a.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		return gw.String()[before:]
+	}
+
+	n := flood()
+	if n == 0 {
+		t.Fatal("nothing dropped")
+	}
+	if got, want := lines("first"), report(n)+"INFO  first\n"; got != want {
+		t.Errorf("first entry after drops wrote %q, want %q", got, want)
+	}
+	n = flood()
+	if got, want := lines("soon"), "INFO  soon\n"; got != want {
+		t.Errorf("entry within the interval wrote %q, want %q", got, want)
+	}
+	now = now.Add(dropReportInterval)
+	if got, want := lines("later"), report(n)+"INFO  later\n"; got != want {
+		t.Errorf("entry after the interval wrote %q, want %q", got, want)
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("Close after the reports = %v", err)
+	}
+}
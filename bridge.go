@@ -0,0 +1,120 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// A LevelPattern recognizes lines at Level. The text matched by Re is
+// removed from the message.
+type LevelPattern struct {
+	Re    *regexp.Regexp
+	Level Level
+}
+
+// DefaultLevelPatterns recognize common level prefixes such as "ERROR:",
+// "[warn]" and "WARNING ".
+var DefaultLevelPatterns = []LevelPattern{
+	{regexp.MustCompile(`(?i)^\s*(?:\[trace\]|trace\b:?)\s*`), LevelTrace},
+	{regexp.MustCompile(`(?i)^\s*(?:\[(?:debug|dbg)\]|(?:debug|dbg)\b:?)\s*`), LevelDebug},
+	{regexp.MustCompile(`(?i)^\s*(?:\[(?:info|notice)\]|(?:info|notice)\b:?)\s*`), LevelInfo},
+	{regexp.MustCompile(`(?i)^\s*(?:\[(?:warn|warning)\]|(?:warn|warning)\b:?)\s*`), LevelWarn},
+	{regexp.MustCompile(`(?i)^\s*(?:\[(?:error|err|fatal|crit|critical|panic)\]|(?:error|err|fatal|crit|critical|panic)\b:?)\s*`), LevelError},
+}
+
+// A BridgeWriter feeds the line oriented output of other code, such as a
+// third-party library or a child process, into a logger. Each line is
+// logged at the level of the first pattern it matches, or at a default
+// level.
+type BridgeWriter struct {
+	log      *Logger
+	def      Level
+	patterns []LevelPattern
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewBridgeWriter creates a writer logging lines to log. If patterns is nil
+// it uses DefaultLevelPatterns.
+func NewBridgeWriter(log *Logger, def Level, patterns []LevelPattern) *BridgeWriter {
+	if patterns == nil {
+		patterns = DefaultLevelPatterns
+	}
+	return &BridgeWriter{log: log, def: def, patterns: patterns}
+}
+
+// Write logs the complete lines in p and buffers the rest.
+func (w *BridgeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	// Compact so the buffer does not creep forward forever.
+	w.buf = append(w.buf[:0:0], w.buf...)
+	return len(p), nil
+}
+
+// Close logs a final incomplete line.
+func (w *BridgeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.line(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *BridgeWriter) line(b []byte) {
+	b = bytes.TrimRight(b, "\r")
+	l := w.def
+	for _, p := range w.patterns {
+		if loc := p.Re.FindIndex(b); loc != nil {
+			l = p.Level
+			b = append(b[:loc[0]:loc[0]], b[loc[1]:]...)
+			break
+		}
+	}
+	w.log.output(3+stdlibFrames(), l, "", string(b), nil)
+}
+
+// stdlibFrames returns the number of frames of the standard library's log
+// and fmt packages calling Write, so that lines they write are attributed
+// to their caller. Called from line.
+func stdlibFrames() int {
+	var pcs [8]uintptr
+	// Skip Callers, this function, line and Write or Close.
+	n := runtime.Callers(4, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	i := 0
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "log.") && !strings.HasPrefix(f.Function, "fmt.") {
+			break
+		}
+		i++
+		if !more {
+			break
+		}
+	}
+	return i
+}
+
+// Writer returns a writer logging each line written to it at level l,
+// for code that takes an io.Writer such as the Stderr of an exec.Cmd.
+// Close logs a final incomplete line.
+func (log *Logger) Writer(l Level) io.WriteCloser {
+	return NewBridgeWriter(log, l, []LevelPattern{})
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Buffers larger than this are left to the garbage collector rather than
+// pooled, so a single huge entry does not pin its memory.
+const maxPooled = 64 << 10
+
+// encodings holds the encodings of one entry, shared by its outputs.
+type encodings struct {
+	plain []byte // in the format of the logger
+	color []byte // in the format of the logger, colored
+	route []byte // with the encoder of a route
+
+	written int // bytes written, for Metrics
+}
+
+var encodingsPool = sync.Pool{New: func() interface{} { return new(encodings) }}
+
+func getEncodings() *encodings {
+	b := encodingsPool.Get().(*encodings)
+	b.plain = b.plain[0:0]
+	b.color = b.color[0:0]
+	b.route = b.route[0:0]
+	b.written = 0
+	return b
+}
+
+func putEncodings(b *encodings) {
+	if cap(b.plain) > maxPooled {
+		b.plain = nil
+	}
+	if cap(b.color) > maxPooled {
+		b.color = nil
+	}
+	if cap(b.route) > maxPooled {
+		b.route = nil
+	}
+	encodingsPool.Put(b)
+}
+
+// sprint is fmt.Sprint without its allocations for a single string.
+func sprint(v []interface{}) string {
+	if len(v) == 1 {
+		if s, ok := v[0].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(v...)
+}
+
+// sprintf is fmt.Sprintf without its allocations for a constant format.
+func sprintf(format string, v []interface{}) string {
+	if len(v) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, v...)
+}
+
+// discards reports whether entries at level l are dropped by log, so the
+// logging methods need not format them.
+func (log *Logger) discards(l Level) bool {
+	log.Lock()
+	min := log.minLevel()
+	log.Unlock()
+	if pl := log.root.pkgLevels.Load(); pl != nil && pl.lowest < min {
+		// Left to outputAt, which knows the caller.
+		min = pl.lowest
+	}
+	return l < min && !log.root.debugEnabled(l, log.now()) && !log.records(l)
+}
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// raceEnabled is set by the race detector, which allocates of its own.
+var raceEnabled bool
+
+func TestAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted under the race detector")
+	}
+	tests := []struct {
+		name string
+		enc  Encoder
+		log  func(*Logger)
+		max  float64
+	}{
+		{"Info", nil, func(l *Logger) { l.Info("request done") }, 0},
+		{"Info json", &JSONEncoder{}, func(l *Logger) { l.Info("request done") }, 0},
+		{"Infof", nil, func(l *Logger) { l.Infof("request %d done", 1000) }, 1},
+		{"Infof constant", nil, func(l *Logger) { l.Infof("request done") }, 0},
+		{"Debug discarded", nil, func(l *Logger) { l.Debug("request done") }, 0},
+		{"Debugf discarded", nil, func(l *Logger) { l.Debugf("request %d done", 1000) }, 0},
+		{"DebugT discarded", nil, func(l *Logger) { l.DebugT("request {n} done", Int("n", 1000), Str("path", "/")) }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(io.Discard, LevelInfo, 0, nil)
+			l.SetEncoder(tt.enc)
+			if n := testing.AllocsPerRun(100, func() { tt.log(l) }); n > tt.max {
+				t.Errorf("%v allocations, want at most %v", n, tt.max)
+			}
+		})
+	}
+}
+
+func TestConcurrentEncoding(t *testing.T) {
+	for _, enc := range []Encoder{nil, &JSONEncoder{}, &LogfmtEncoder{}} {
+		var buf bytes.Buffer
+		l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+		l.SetEncoder(enc)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					l.Info("request done", F("n", j))
+				}
+			}()
+		}
+		wg.Wait()
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if len(lines) != 800 {
+			t.Fatalf("%T: %d lines, want 800", enc, len(lines))
+		}
+		for _, line := range lines {
+			if strings.Count(line, "request done") != 1 {
+				t.Fatalf("%T: garbled line %q", enc, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// BugsnagConfig configures a Bugsnag sink.
+type BugsnagConfig struct {
+	APIKey       string
+	ReleaseStage string
+	// Endpoint defaults to https://notify.bugsnag.com/.
+	Endpoint string
+	// DedupWindow is how long an entry logged from the same place is not
+	// reported again, one minute by default.
+	DedupWindow time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Bugsnag is a sink reporting error entries, with the stack FlagStack
+// or LogPanic added to them, to Bugsnag.
+type Bugsnag struct {
+	*reporter
+	c BugsnagConfig
+}
+
+// NewBugsnag creates a Bugsnag sink. Close flushes it.
+func NewBugsnag(c BugsnagConfig) *Bugsnag {
+	if c.Endpoint == "" {
+		c.Endpoint = "https://notify.bugsnag.com/"
+	}
+	b := &Bugsnag{c: c}
+	b.reporter = newReporter(LevelError, c.DedupWindow, c.Client, b.build)
+	return b
+}
+
+type bugsnagFrame struct {
+	File       string `json:"file"`
+	LineNumber int    `json:"lineNumber"`
+	Method     string `json:"method"`
+}
+
+func (b *Bugsnag) build(rep *report) (*http.Request, error) {
+	frames := make([]bugsnagFrame, len(rep.Stack))
+	for i, f := range rep.Stack {
+		frames[i] = bugsnagFrame{f.File, f.Line, f.Function}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"apiKey":         b.c.APIKey,
+		"payloadVersion": "5",
+		"notifier": map[string]string{
+			"name":    "go-log",
+			"version": "1",
+			"url":     "https://github.com/lucy/go-log",
+		},
+		"events": []interface{}{
+			map[string]interface{}{
+				"severity":     "error",
+				"unhandled":    false,
+				"groupingHash": rep.Fingerprint,
+				"app":          map[string]string{"releaseStage": b.c.ReleaseStage},
+				"metaData":     map[string]interface{}{"fields": rep.Fields},
+				"exceptions": []interface{}{
+					map[string]interface{}{
+						"errorClass": "error",
+						"message":    rep.Message,
+						"stacktrace": frames,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", b.c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Bugsnag-Api-Key", b.c.APIKey)
+	req.Header.Set("Bugsnag-Payload-Version", "5")
+	req.Header.Set("Bugsnag-Sent-At", time.Now().UTC().Format(time.RFC3339))
+	return req, nil
+}
@@ -0,0 +1,44 @@
+package log
+
+// Enabled reports whether log writes entries at level l, or keeps them
+// for the flight recorder, so callers can skip building what they would
+// log. Sampling, rate limits and filters may still drop such entries.
+func (log *Logger) Enabled(l Level) bool {
+	return log.enabled(l)
+}
+
+// enabled is Enabled for the caller of its caller.
+func (log *Logger) enabled(l Level) bool {
+	var pc uintptr
+	log.Lock()
+	min := log.callerMinLevel(3, &pc)
+	log.Unlock()
+	return l >= min || log.root.debugEnabled(l, log.now()) || log.records(l)
+}
+
+// A CheckedEntry is an entry that passed the level check of Check.
+type CheckedEntry struct {
+	log   *Logger
+	level Level
+}
+
+// Check returns an entry at level l to be written with Write, or nil if
+// log discards entries at l:
+//
+//	if ce := log.Check(log.LevelDebug); ce != nil {
+//		ce.Write("state", log.F("dump", dump(state)))
+//	}
+func (log *Logger) Check(l Level) *CheckedEntry {
+	if !log.enabled(l) {
+		return nil
+	}
+	return &CheckedEntry{log, l}
+}
+
+// Write writes the entry with message msg and fields.
+func (ce *CheckedEntry) Write(msg string, fields ...Field) {
+	if g := ce.log.group; g != "" {
+		fields = groupFields(g, fields)
+	}
+	ce.log.output(2, ce.level, "", msg, fields)
+}
@@ -0,0 +1,22 @@
+package log
+
+import "time"
+
+// SetClock makes log and the loggers derived from it take the times of
+// entries from now instead of time.Now, for example to get reproducible
+// output in tests. Nil restores time.Now.
+func (log *Logger) SetClock(now func() time.Time) {
+	if now == nil {
+		log.root.clock.Store(nil)
+		return
+	}
+	log.root.clock.Store(&now)
+}
+
+// now returns the current time by the clock of log.
+func (log *Logger) now() time.Time {
+	if f := log.root.clock.Load(); f != nil {
+		return (*f)()
+	}
+	return time.Now()
+}
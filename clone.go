@@ -0,0 +1,66 @@
+package log
+
+// Clone returns an independent copy of log: a root logger of its own with
+// log's settings, bound fields, name and prefix, writing to the same
+// outputs, routes and sinks. Changing the settings of either afterwards,
+// such as the output, level or encoder, leaves the other alone. Sampling
+// and rate limit counts start afresh, and the clone owns nothing, so
+// closing it leaves the outputs open.
+func (log *Logger) Clone() *Logger {
+	r := log.root
+	r.Lock()
+	c := &Logger{
+		out:        r.out,
+		tty:        r.tty,
+		pre:        r.pre,
+		flag:       r.flag,
+		stackMin:   r.stackMin,
+		onError:    r.onError,
+		closed:     r.closed,
+		enc:        r.enc,
+		sinks:      r.sinks[:len(r.sinks):len(r.sinks)],
+		routes:     r.routes[:len(r.routes):len(r.routes)],
+		filters:    r.filters[:len(r.filters):len(r.filters)],
+		hooks:      r.hooks[:len(r.hooks):len(r.hooks)],
+		afterHooks: r.afterHooks[:len(r.afterHooks):len(r.afterHooks)],
+		ctxKeys:    r.ctxKeys[:len(r.ctxKeys):len(r.ctxKeys)],
+		redact:     r.redact[:len(r.redact):len(r.redact)],
+		metrics:    r.metrics,
+		span:       r.span,
+		text:       r.text,
+	}
+	if r.dup != nil {
+		c.dup = &dedup{window: r.dup.window}
+	}
+	c.debug.Store(r.debug.Load())
+	// The level maps are copied on write and can be shared.
+	c.nameLevels.Store(r.nameLevels.Load())
+	c.pkgLevels.Store(r.pkgLevels.Load())
+	c.clock.Store(r.clock.Load())
+	if fr := r.flight.Load(); fr != nil {
+		c.flight.Store(&flightRecorder{min: fr.min, entries: make([]Entry, len(fr.entries))})
+	}
+	r.Unlock()
+
+	log.Lock()
+	defer log.Unlock()
+	c.root = c
+	set := log.settings()
+	if ms := set.msgs; ms != nil {
+		set.msgs = &msgSampler{window: ms.window, first: ms.first, thereafter: ms.thereafter}
+	}
+	c.shared.Store(&set)
+	c.prefix = log.prefix
+	c.name = log.name
+	c.fields = log.fields[:len(log.fields):len(log.fields)]
+	c.group = log.group
+	c.skip = log.skip
+	c.exempt = log.exempt
+	if log.lim != nil {
+		c.lim = newLimiter(log.lim.rate, int(log.lim.burst))
+	}
+	if kl := log.keyLim; kl != nil {
+		c.keyLim = &keyLimiter{key: kl.key, rate: kl.rate, burst: kl.burst, m: make(map[string]*limiter)}
+	}
+	return c
+}
@@ -0,0 +1,60 @@
+// Command logdecrypt decrypts log files written through log.EncryptWriter.
+//
+// Usage:
+//
+//	logdecrypt -key keyfile [file ...]
+//
+// The key file holds the hex-encoded master key. With no files, logdecrypt
+// reads standard input. Plain text is written to standard output. Damaged
+// files, and files not closed by their writer such as those still being
+// written, are reported after what could be decrypted.
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/lucy/go-log"
+)
+
+func main() {
+	keyFile := flag.String("key", "", "file containing the hex-encoded key")
+	flag.Parse()
+	if *keyFile == "" {
+		fmt.Fprintln(os.Stderr, "usage: logdecrypt -key keyfile [file ...]")
+		os.Exit(2)
+	}
+	b, err := os.ReadFile(*keyFile)
+	if err != nil {
+		fatal(err)
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil {
+		fatal(fmt.Errorf("%s: %v", *keyFile, err))
+	}
+	if flag.NArg() == 0 {
+		if err := log.Decrypt(os.Stdout, os.Stdin, key); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	for _, name := range flag.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fatal(err)
+		}
+		err = log.Decrypt(os.Stdout, f, key)
+		f.Close()
+		if err != nil {
+			fatal(fmt.Errorf("%s: %v", name, err))
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "logdecrypt:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,387 @@
+//go:build !windows
+
+// Command logview is a terminal viewer for logs written by this package.
+//
+// Usage:
+//
+//	logview [-listen addr] [-http addr] [file ...]
+//
+// It follows the named files like tail -f and, with -listen or -http,
+// receives entries sent over TCP or HTTP like log.Receiver. Keys:
+//
+//	t d i w e show entries from trace, debug, info, warn or error up
+//	/         search messages and fields; enter applies, escape cancels
+//	n         clear the search
+//	space     pause or resume following
+//	↑ ↓ PgUp PgDn  scroll while paused
+//	q         quit
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/lucy/go-log"
+)
+
+const maxLines = 10000
+
+type line struct {
+	level log.Level
+	text  string
+}
+
+type viewer struct {
+	mu      sync.Mutex
+	lines   []line
+	min     log.Level
+	query   string
+	editing bool
+	input   string
+	paused  bool
+	scroll  int
+	dirty   bool
+	enc     log.TextEncoder
+}
+
+// WriteEntry implements log.Sink.
+func (v *viewer) WriteEntry(e *log.Entry) error {
+	b := v.enc.Encode(nil, e)
+	text := strings.TrimRight(string(b), "\n")
+	l := line{e.Level, text}
+	v.mu.Lock()
+	v.lines = append(v.lines, l)
+	if v.paused && v.match(l) {
+		// Keep the paused view where it is.
+		v.scroll++
+	}
+	if len(v.lines) > maxLines {
+		v.lines = append(v.lines[:0], v.lines[len(v.lines)-maxLines:]...)
+	}
+	v.dirty = true
+	v.mu.Unlock()
+	return nil
+}
+
+// follow feeds the lines of the file at path to the viewer as it grows.
+func (v *viewer) follow(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		v.status(err.Error())
+		return
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var off int64
+	var partial []byte
+	for {
+		b, err := r.ReadBytes('\n')
+		off += int64(len(b))
+		partial = append(partial, b...)
+		if err == nil {
+			v.decode(partial)
+			partial = partial[:0]
+			continue
+		}
+		if err != io.EOF {
+			v.status(path + ": " + err.Error())
+			return
+		}
+		time.Sleep(250 * time.Millisecond)
+		if fi, err := f.Stat(); err == nil && fi.Size() < off {
+			// Truncated or rotated in place.
+			f.Seek(0, io.SeekStart)
+			r.Reset(f)
+			off = 0
+			partial = partial[:0]
+		}
+	}
+}
+
+func (v *viewer) decode(b []byte) {
+	var e log.Entry
+	if log.DecodeEntry(b, &e) == nil {
+		v.WriteEntry(&e)
+	}
+}
+
+func (v *viewer) status(msg string) {
+	v.WriteEntry(&log.Entry{Level: log.LevelError, Time: time.Now(), Message: "logview: " + msg})
+}
+
+func (v *viewer) match(l line) bool {
+	return l.level >= v.min && (v.query == "" || strings.Contains(l.text, v.query))
+}
+
+func (v *viewer) visible() []line {
+	var out []line
+	for _, l := range v.lines {
+		if v.match(l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+var colors = map[log.Level]string{
+	log.LevelTrace: "\x1b[2m",
+	log.LevelDebug: "\x1b[90m",
+	log.LevelWarn:  "\x1b[33m",
+	log.LevelError: "\x1b[31m",
+}
+
+func truncate(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+func (v *viewer) render(w io.Writer, width, height int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.dirty = false
+	lines := v.visible()
+	rows := height - 1
+	end := len(lines)
+	if v.paused {
+		if v.scroll > end-rows {
+			v.scroll = end - rows
+		}
+		if v.scroll < 0 {
+			v.scroll = 0
+		}
+		end -= v.scroll
+	}
+	start := end - rows
+	if start < 0 {
+		start = 0
+	}
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	for _, l := range lines[start:end] {
+		b.WriteString(colors[l.level])
+		b.WriteString(truncate(strings.ReplaceAll(l.text, "\t", " "), width))
+		b.WriteString("\x1b[0m\r\n")
+	}
+	for i := end - start; i < rows; i++ {
+		b.WriteString("\r\n")
+	}
+	var st string
+	switch {
+	case v.editing:
+		st = "/" + v.input
+	default:
+		st = fmt.Sprintf("level>=%s %d/%d", levelName(v.min), len(lines), len(v.lines))
+		if v.query != "" {
+			st += " search=" + strconv.Quote(v.query)
+		}
+		if v.paused {
+			st += " PAUSED"
+			if v.scroll > 0 {
+				st += fmt.Sprintf(" -%d", v.scroll)
+			}
+		}
+	}
+	b.WriteString("\x1b[7m")
+	b.WriteString(truncate(st, width))
+	for i := utf8.RuneCountInString(st); i < width; i++ {
+		b.WriteByte(' ')
+	}
+	b.WriteString("\x1b[0m")
+	io.WriteString(w, b.String())
+}
+
+func levelName(l log.Level) string {
+	return strings.ToUpper(l.String())
+}
+
+// key handles a key press and reports whether to quit.
+func (v *viewer) key(k string, height int) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.dirty = true
+	if v.editing {
+		switch k {
+		case "\r", "\n":
+			v.query = v.input
+			v.editing = false
+		case "\x1b":
+			v.editing = false
+		case "\x7f", "\b":
+			if n := len(v.input); n > 0 {
+				_, size := utf8.DecodeLastRuneInString(v.input)
+				v.input = v.input[:n-size]
+			}
+		default:
+			if len(k) == 1 && k[0] >= ' ' || utf8.RuneCountInString(k) == 1 && k[0] >= utf8.RuneSelf {
+				v.input += k
+			}
+		}
+		return false
+	}
+	page := height - 1
+	switch k {
+	case "q", "\x03":
+		return true
+	case "t":
+		v.min = log.LevelTrace
+	case "d":
+		v.min = log.LevelDebug
+	case "i":
+		v.min = log.LevelInfo
+	case "w":
+		v.min = log.LevelWarn
+	case "e":
+		v.min = log.LevelError
+	case "/":
+		v.editing = true
+		v.input = v.query
+	case "n":
+		v.query = ""
+	case " ":
+		v.paused = !v.paused
+		v.scroll = 0
+	case "\x1b[A":
+		v.paused = true
+		v.scroll++
+	case "\x1b[B":
+		v.scroll--
+	case "\x1b[5~":
+		v.paused = true
+		v.scroll += page
+	case "\x1b[6~":
+		v.scroll -= page
+	}
+	return false
+}
+
+// readKeys sends key presses, with escape sequences kept together.
+func readKeys(r io.Reader, keys chan<- string) {
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err != nil {
+			close(keys)
+			return
+		}
+		k := string(c)
+		if c == 0x1b && br.Buffered() > 0 {
+			seq := []byte{0x1b}
+			for br.Buffered() > 0 {
+				b, _ := br.ReadByte()
+				seq = append(seq, b)
+				if len(seq) > 2 && (b >= 'A' && b <= 'Z' || b == '~') {
+					break
+				}
+			}
+			k = string(seq)
+		}
+		keys <- k
+	}
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func termSize() (int, int) {
+	out, err := stty("size")
+	if err == nil {
+		var h, w int
+		if _, err := fmt.Sscan(out, &h, &w); err == nil && h > 1 && w > 0 {
+			return w, h
+		}
+	}
+	return 80, 24
+}
+
+func main() {
+	listen := flag.String("listen", "", "receive entries over TCP on `addr`")
+	httpAddr := flag.String("http", "", "receive entries over HTTP on `addr`")
+	flag.Parse()
+	if flag.NArg() == 0 && *listen == "" && *httpAddr == "" {
+		fmt.Fprintln(os.Stderr, "usage: logview [-listen addr] [-http addr] [file ...]")
+		os.Exit(2)
+	}
+	v := &viewer{enc: log.TextEncoder{Flags: log.FlagShortPath}}
+	for _, path := range flag.Args() {
+		go v.follow(path)
+	}
+	if *listen != "" || *httpAddr != "" {
+		logger := log.New(nil, log.LevelDebug, 0, nil)
+		logger.AddSink(v)
+		rv := log.NewReceiver(logger)
+		if *listen != "" {
+			ln, err := net.Listen("tcp", *listen)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "logview:", err)
+				os.Exit(1)
+			}
+			go rv.Serve(ln)
+		}
+		if *httpAddr != "" {
+			go func() {
+				if err := http.ListenAndServe(*httpAddr, rv); err != nil {
+					v.status(err.Error())
+				}
+			}()
+		}
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "logview: standard input is not a terminal")
+		os.Exit(1)
+	}
+	stty("raw", "-echo")
+	defer func() {
+		stty(saved)
+		fmt.Print("\x1b[H\x1b[2J")
+	}()
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[?25h")
+
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	width, height := termSize()
+	v.render(os.Stdout, width, height)
+	for n := 0; ; n++ {
+		select {
+		case k, ok := <-keys:
+			if !ok || v.key(k, height) {
+				return
+			}
+		case <-tick.C:
+			if n%10 == 0 {
+				width, height = termSize()
+			}
+			v.mu.Lock()
+			dirty := v.dirty && !v.paused
+			v.mu.Unlock()
+			if !dirty {
+				continue
+			}
+		}
+		v.render(os.Stdout, width, height)
+	}
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+const colorReset = "\x1b[0m"
+
+// A Theme chooses the ANSI escape sequences, such as "\x1b[1;31m" for
+// bold red, that start each colored part of the text format. Empty
+// sequences leave a part plain.
+type Theme struct {
+	// Levels are in the order of LevelStrings. Registered levels take
+	// the color of the predefined level below them.
+	Levels [len(LevelStrings{})]string
+	Time   string
+	Caller string // with FlagColorCaller
+}
+
+// DefaultTheme is the theme used unless another is set.
+var DefaultTheme = Theme{
+	Levels: [len(LevelStrings{})]string{
+		"\x1b[90m", // debug
+		"\x1b[36m", // info
+		"\x1b[33m", // warn
+		"\x1b[31m", // error
+		"\x1b[2m",  // trace
+	},
+	Caller: "\x1b[2m",
+}
+
+// SGR returns the escape sequence selecting the graphic rendition
+// parameters, as in SGR(1, 31) for bold red or SGR(38, 5, 208) for color
+// 208 of the 256 color palette.
+func SGR(params ...int) string {
+	b := []byte("\x1b[")
+	for i, p := range params {
+		if i > 0 {
+			b = append(b, ';')
+		}
+		b = strconv.AppendInt(b, int64(p), 10)
+	}
+	return string(append(b, 'm'))
+}
+
+// SetTheme sets the colors used with FlagColor. Nil selects DefaultTheme.
+func (log *Logger) SetTheme(t *Theme) {
+	r := log.root
+	r.Lock()
+	r.text.Theme = t
+	r.Unlock()
+}
+
+// appendColored appends s wrapped in the escape sequence color, if any.
+func appendColored(buf []byte, color, s string) []byte {
+	if color == "" {
+		return append(buf, s...)
+	}
+	buf = append(buf, color...)
+	buf = append(buf, s...)
+	return append(buf, colorReset...)
+}
+
+// isTerminal reports whether w is a terminal that may be colored.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// A Config describes a logger to build, for example from command line
+// flags with RegisterFlags.
+type Config struct {
+	Level Level
+	// Format is the name of the output format, "text", "logfmt", "json",
+	// "otel", "msgpack" or "pretty". Empty means text.
+	Format string
+	// Output is "stderr", "stdout" or the path of a file to append to,
+	// which Reopen reopens. Empty means standard error.
+	Output string
+	Flags  Flags
+}
+
+// formats maps format names to encoder constructors.
+var formats = map[string]func(c *Config) Encoder{
+	"text":    func(c *Config) Encoder { return nil },
+	"logfmt":  func(c *Config) Encoder { return &LogfmtEncoder{Flags: c.Flags} },
+	"json":    func(c *Config) Encoder { return &JSONEncoder{Flags: c.Flags} },
+	"otel":    func(c *Config) Encoder { return NewOTelEncoder(nil) },
+	"msgpack": func(c *Config) Encoder { return &MsgpackEncoder{Flags: c.Flags} },
+	"pretty":  func(c *Config) Encoder { return &PrettyEncoder{Flags: c.Flags} },
+}
+
+// Build creates the logger described by c. A file it opens is owned by the
+// logger and closed with it.
+func (c *Config) Build() (*Logger, error) {
+	format := c.Format
+	if format == "" {
+		format = "text"
+	}
+	newEnc, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("log: unknown format %q", c.Format)
+	}
+	var log *Logger
+	switch c.Output {
+	case "", "stderr":
+		log = New(os.Stderr, c.Level, c.Flags, nil)
+	case "stdout":
+		log = New(os.Stdout, c.Level, c.Flags, nil)
+	default:
+		f, err := OpenFile(c.Output, 0644)
+		if err != nil {
+			return nil, err
+		}
+		log = New(f, c.Level, c.Flags, nil)
+		log.Own(f)
+	}
+	if enc := newEnc(c); enc != nil {
+		if p, ok := enc.(*PrettyEncoder); ok {
+			p.Color = log.tty && c.Flags&FlagColor != 0
+		}
+		log.SetEncoder(enc)
+	}
+	return log, nil
+}
+
+// RegisterFlags defines the flags -log-level, -log-format and -log-output
+// on fs, or on flag.CommandLine if fs is nil, and returns the Config they
+// set. The defaults are info, text and stderr.
+//
+//	conf := log.RegisterFlags(nil)
+//	flag.Parse()
+//	logger, err := conf.Build()
+func RegisterFlags(fs *flag.FlagSet) *Config {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	c := &Config{Level: LevelInfo, Format: "text", Output: "stderr"}
+	fs.TextVar(&c.Level, "log-level", c.Level, "minimum log `level`: trace, debug, info, warn or error")
+	fs.StringVar(&c.Format, "log-format", c.Format, "log `format`: text, logfmt, json, otel, msgpack or pretty")
+	fs.StringVar(&c.Output, "log-output", c.Output, "log to stderr, stdout or the named `file`")
+	return c
+}
+
+// ConfigFromEnv returns the Config described by the environment variables
+//
+//	LOG_LEVEL   minimum level, default info
+//	LOG_FORMAT  text, logfmt, json, otel, msgpack or pretty, default
+//	            text
+//	LOG_OUTPUT  stderr, stdout or the path of a file, default stderr
+//	LOG_COLOR   whether to color terminals, a boolean, default true
+//	LOG_CALLER  none, short or long path of the caller, default none
+//
+// Colors are only used on terminals and never if NO_COLOR is set.
+func ConfigFromEnv() (*Config, error) {
+	c := &Config{Level: LevelInfo, Format: "text", Output: "stderr", Flags: FlagColor}
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		l, err := ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("log: LOG_LEVEL: unknown level %q", s)
+		}
+		c.Level = l
+	}
+	if s := os.Getenv("LOG_FORMAT"); s != "" {
+		if _, ok := formats[s]; !ok {
+			return nil, fmt.Errorf("log: LOG_FORMAT: unknown format %q", s)
+		}
+		c.Format = s
+	}
+	if s := os.Getenv("LOG_OUTPUT"); s != "" {
+		c.Output = s
+	}
+	if s := os.Getenv("LOG_COLOR"); s != "" {
+		color, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("log: LOG_COLOR: invalid boolean %q", s)
+		}
+		if !color {
+			c.Flags &^= FlagColor
+		}
+	}
+	switch s := os.Getenv("LOG_CALLER"); s {
+	case "", "none":
+	case "short":
+		c.Flags |= FlagShortPath
+	case "long":
+		c.Flags |= FlagLongPath
+	default:
+		return nil, fmt.Errorf("log: LOG_CALLER: unknown caller %q", s)
+	}
+	return c, nil
+}
+
+// NewFromEnv creates the logger described by the environment, see
+// ConfigFromEnv.
+func NewFromEnv() (*Logger, error) {
+	c, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return c.Build()
+}
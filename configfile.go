@@ -0,0 +1,191 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// A FileConfig is the logging policy of a configuration file, such as
+//
+//	{
+//		"level": "info",
+//		"levels": {"db": "debug", "http.access": "warn"},
+//		"format": "json",
+//		"output": "/var/log/svc.log",
+//		"sample_rates": {"debug": 0.01},
+//		"sampling": {"window": "1s", "first": 100, "thereafter": 10}
+//	}
+//
+// Settings left out are left as they are.
+type FileConfig struct {
+	Level *Level `json:"level"`
+	// Levels are the minimum levels by logger name, see SetNameLevel.
+	Levels map[string]Level `json:"levels"`
+	// Format and Output are as in Config.
+	Format      string            `json:"format"`
+	Output      string            `json:"output"`
+	SampleRates map[Level]float64 `json:"sample_rates"`
+	Sampling    *SamplingConfig   `json:"sampling"`
+}
+
+// A SamplingConfig holds the arguments of SetSampling.
+type SamplingConfig struct {
+	Window     string `json:"window"` // as parsed by time.ParseDuration
+	First      int    `json:"first"`
+	Thereafter int    `json:"thereafter"`
+}
+
+// ReadConfig parses the JSON configuration file at path.
+func ReadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	fc := new(FileConfig)
+	if err := dec.Decode(fc); err != nil {
+		return nil, fmt.Errorf("log: %s: %v", path, err)
+	}
+	return fc, nil
+}
+
+// ApplyConfig applies fc to log's root logger. Levels replaces the levels
+// set with SetNameLevel and SampleRates those set with SetSampleRate. A
+// file named by Output replaces the output of the root logger and is
+// closed when the output next changes or the logger is closed. The level
+// and sampling reach the loggers derived from the root logger, also those
+// made before, unless they have their own from WithMinLevel,
+// SetSampleRate, SetSampling or Tenants.SetLevel.
+func (log *Logger) ApplyConfig(fc *FileConfig) error {
+	var window time.Duration
+	if s := fc.Sampling; s != nil && s.Window != "" {
+		var err error
+		if window, err = time.ParseDuration(s.Window); err != nil {
+			return fmt.Errorf("log: sampling window: %v", err)
+		}
+	}
+	r := log.root
+	var enc Encoder
+	if fc.Format != "" {
+		newEnc, ok := formats[fc.Format]
+		if !ok {
+			return fmt.Errorf("log: unknown format %q", fc.Format)
+		}
+		r.Lock()
+		enc = newEnc(&Config{Flags: r.flag})
+		r.Unlock()
+	}
+	var out io.Writer
+	var file io.Closer
+	switch fc.Output {
+	case "":
+	case "stderr":
+		out = os.Stderr
+	case "stdout":
+		out = os.Stdout
+	default:
+		f, err := OpenFile(fc.Output, 0644)
+		if err != nil {
+			return err
+		}
+		out, file = f, f
+	}
+
+	r.Lock()
+	set := *r.shared.Load()
+	if fc.Level != nil {
+		set.min = *fc.Level
+	}
+	if fc.Format != "" {
+		r.enc = enc
+	}
+	var old io.Closer
+	if out != nil {
+		r.out, r.tty = out, isTerminal(out)
+		old, r.confOut = r.confOut, file
+	}
+	if fc.SampleRates != nil {
+		m := make(map[Level]float64, len(fc.SampleRates))
+		for l, rate := range fc.SampleRates {
+			if rate < 1 {
+				m[l] = rate
+			}
+		}
+		if len(m) == 0 {
+			m = nil
+		}
+		set.sample = m
+	}
+	if s := fc.Sampling; s != nil {
+		if window <= 0 {
+			set.msgs = nil
+		} else {
+			set.msgs = &msgSampler{window: window, first: s.First, thereafter: s.Thereafter}
+		}
+	}
+	// Shared with the derived loggers, which see the change at once.
+	r.shared.Store(&set)
+	if fc.Levels != nil {
+		m := make(map[string]Level, len(fc.Levels))
+		for k, v := range fc.Levels {
+			m[k] = v
+		}
+		r.nameLevels.Store(&m)
+	}
+	r.Unlock()
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+// LoadConfig reads the configuration file at path and applies it to log.
+func (log *Logger) LoadConfig(path string) error {
+	fc, err := ReadConfig(path)
+	if err != nil {
+		return err
+	}
+	return log.ApplyConfig(fc)
+}
+
+// WatchConfig loads the configuration file at path and then checks every
+// interval whether it changed, loading it again if so. An invalid file is
+// logged as an error and leaves the previous settings in place. The
+// returned stop function ends the watching.
+func (log *Logger) WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := log.LoadConfig(path); err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+			}
+			cur, err := os.Stat(path)
+			if err != nil || (cur.ModTime().Equal(fi.ModTime()) && cur.Size() == fi.Size()) {
+				continue
+			}
+			fi = cur
+			if err := log.LoadConfig(path); err != nil {
+				log.Errorf("reloading config: %v", err)
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }, nil
+}
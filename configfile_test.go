@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigReachesDerived(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, 0, nil)
+	loggers := []struct {
+		name string
+		log  *Logger
+		want bool // debug entries written after the reload
+	}{
+		{"root", l, true},
+		{"With", l.With("req", 1), true},
+		{"WithPrefix", l.WithPrefix("[db] "), true},
+		{"Named", l.Named("http"), true},
+		{"derived twice", l.With("req", 1).Named("http"), true},
+		{"WithMinLevel", l.WithMinLevel(LevelWarn), false},
+		{"below WithMinLevel", l.WithMinLevel(LevelWarn).With("req", 1), false},
+	}
+	path := filepath.Join(t.TempDir(), "log.json")
+	if err := os.WriteFile(path, []byte(`{"level": "debug"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.LoadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range loggers {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.log.Debug("reloaded")
+			if got := strings.Contains(buf.String(), "reloaded"); got != tt.want {
+				t.Errorf("debug entry written = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigSamplingReachesDerived(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, 0, nil)
+	child := l.With("req", 1)
+	own := l.With("req", 2)
+	own.SetSampleRate(LevelInfo, 1)
+	if err := l.ApplyConfig(&FileConfig{SampleRates: map[Level]float64{LevelInfo: 0}}); err != nil {
+		t.Fatal(err)
+	}
+	child.Info("sampled out")
+	if buf.Len() != 0 {
+		t.Errorf("sample rate not applied to derived logger: %q", buf.String())
+	}
+	own.Info("kept")
+	if !strings.Contains(buf.String(), "kept") {
+		t.Errorf("derived logger with its own sample rates was sampled: %q", buf.String())
+	}
+}
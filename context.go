@@ -0,0 +1,114 @@
+package log
+
+import "context"
+
+type contextKey struct {
+	key  interface{}
+	name string
+}
+
+// AddContextKey makes the context-aware methods of log and the loggers
+// derived from it attach the value stored in their context under key as a
+// field named name, so values like a user or tenant id need not be
+// extracted at every call site.
+func (log *Logger) AddContextKey(key interface{}, name string) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	// Copied on write, see contextFields.
+	keys := make([]contextKey, len(r.ctxKeys), len(r.ctxKeys)+1)
+	copy(keys, r.ctxKeys)
+	r.ctxKeys = append(keys, contextKey{key, name})
+}
+
+// A SpanFunc returns the ids of the trace span carried by ctx, if any, as
+// hexadecimal strings. With OpenTelemetry it would be
+//
+//	func(ctx context.Context) (string, string, bool) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID().String(), sc.SpanID().String(), sc.IsValid()
+//	}
+type SpanFunc func(ctx context.Context) (traceID, spanID string, ok bool)
+
+// SetSpanFunc makes the context-aware methods attach the trace_id and
+// span_id fields of the span found by f, so entries can be correlated
+// with traces. Nil disables it.
+func (log *Logger) SetSpanFunc(f SpanFunc) {
+	r := log.root
+	r.Lock()
+	r.span = f
+	r.Unlock()
+}
+
+// contextFields returns the fields of the registered keys and the span
+// found in ctx.
+func (log *Logger) contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	r := log.root
+	r.Lock()
+	keys := r.ctxKeys
+	span := r.span
+	r.Unlock()
+	var fields []Field
+	if span != nil {
+		if trace, id, ok := span(ctx); ok {
+			fields = append(fields, Field{Key: "trace_id", Value: trace}, Field{Key: "span_id", Value: id})
+		}
+	}
+	for _, k := range keys {
+		if v := ctx.Value(k.key); v != nil {
+			fields = append(fields, Field{Key: k.name, Value: v})
+		}
+	}
+	return fields
+}
+
+// LogContext is Log with fields taken from ctx. See AddContextKey.
+func (log *Logger) LogContext(ctx context.Context, l Level, v ...interface{}) {
+	if log.discards(l) {
+		return
+	}
+	log.output(2, l, "", sprint(v), log.contextFields(ctx))
+}
+
+// TraceContext is LogContext at the trace log level.
+func (log *Logger) TraceContext(ctx context.Context, v ...interface{}) {
+	if log.discards(LevelTrace) {
+		return
+	}
+	log.output(2, LevelTrace, "", sprint(v), log.contextFields(ctx))
+}
+
+// DebugContext is LogContext at the debug log level.
+func (log *Logger) DebugContext(ctx context.Context, v ...interface{}) {
+	if log.discards(LevelDebug) {
+		return
+	}
+	log.output(2, LevelDebug, "", sprint(v), log.contextFields(ctx))
+}
+
+// InfoContext is LogContext at the info log level.
+func (log *Logger) InfoContext(ctx context.Context, v ...interface{}) {
+	if log.discards(LevelInfo) {
+		return
+	}
+	log.output(2, LevelInfo, "", sprint(v), log.contextFields(ctx))
+}
+
+// WarnContext is LogContext at the warn log level.
+func (log *Logger) WarnContext(ctx context.Context, v ...interface{}) {
+	if log.discards(LevelWarn) {
+		return
+	}
+	log.output(2, LevelWarn, "", sprint(v), log.contextFields(ctx))
+}
+
+// ErrorContext is LogContext at the error log level.
+func (log *Logger) ErrorContext(ctx context.Context, v ...interface{}) {
+	if log.discards(LevelError) {
+		return
+	}
+	log.output(2, LevelError, "", sprint(v), log.contextFields(ctx))
+}
@@ -0,0 +1,21 @@
+package log
+
+import (
+	"math"
+	"os"
+)
+
+// debugOnSignal enables debug entries when the process receives on and
+// disables them again on off.
+func (log *Logger) debugOnSignal(on, off os.Signal) (stop func()) {
+	r := log.root
+	return onSignal([]os.Signal{on, off}, func(sig os.Signal) {
+		if sig == on {
+			r.debug.Store(math.MaxInt64)
+			r.Info("debug entries enabled by signal")
+		} else {
+			r.debug.Store(0)
+			r.Info("debug entries disabled by signal")
+		}
+	})
+}
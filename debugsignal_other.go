@@ -0,0 +1,10 @@
+//go:build !unix
+
+package log
+
+// DebugOnSignal does nothing, as there are no SIGUSR1 and SIGUSR2 to
+// enable and disable debug entries with. The returned stop function does
+// nothing either.
+func (log *Logger) DebugOnSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the signal handling goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestDebugOnSignal(t *testing.T) {
+	var buf syncBuffer
+	l := New(&buf, LevelInfo, 0, nil)
+	stop := l.DebugOnSignal()
+	defer stop()
+	tests := []struct {
+		sig     syscall.Signal
+		message string
+		debug   bool
+	}{
+		{syscall.SIGUSR1, "debug entries enabled by signal", true},
+		{syscall.SIGUSR2, "debug entries disabled by signal", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sig.String(), func(t *testing.T) {
+			if err := syscall.Kill(syscall.Getpid(), tt.sig); err != nil {
+				t.Fatal(err)
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for !strings.Contains(buf.String(), tt.message) {
+				if time.Now().After(deadline) {
+					t.Fatalf("signal not handled: %q", buf.String())
+				}
+				time.Sleep(time.Millisecond)
+			}
+			l.Debug("probe " + tt.sig.String())
+			if got := strings.Contains(buf.String(), "probe "+tt.sig.String()); got != tt.debug {
+				t.Errorf("debug entry written = %v, want %v", got, tt.debug)
+			}
+		})
+	}
+}
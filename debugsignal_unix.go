@@ -0,0 +1,13 @@
+//go:build unix
+
+package log
+
+import "syscall"
+
+// DebugOnSignal enables debug entries as EnableDebugFor does, but until
+// further notice, when the process receives SIGUSR1, and disables them
+// again on SIGUSR2, so the verbosity of a running process can be changed
+// with kill. The returned stop function stops the handling.
+func (log *Logger) DebugOnSignal() (stop func()) {
+	return log.debugOnSignal(syscall.SIGUSR1, syscall.SIGUSR2)
+}
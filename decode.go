@@ -0,0 +1,194 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// otelRecord is the part of an OTelEncoder record DecodeEntry reads.
+type otelRecord struct {
+	TimeUnixNano   string `json:"timeUnixNano"`
+	SeverityNumber int    `json:"severityNumber"`
+	TraceID        string `json:"traceId"`
+	SpanID         string `json:"spanId"`
+	Body           struct {
+		StringValue string `json:"stringValue"`
+	} `json:"body"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue *string  `json:"stringValue"`
+			IntValue    *string  `json:"intValue"`
+			DoubleValue *float64 `json:"doubleValue"`
+			BoolValue   *bool    `json:"boolValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+}
+
+// DecodeEntry parses a line written by the text format, JSONEncoder with
+// the default keys or OTelEncoder into e. Text lines that do not start with
+// one of the default level strings or the name of a registered level are
+// taken as messages at the info level logged now; their fields stay part
+// of the message.
+func DecodeEntry(line []byte, e *Entry) error {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) > 0 && line[0] == '{' {
+		if bytes.HasPrefix(line, []byte(`{"timeUnixNano":`)) {
+			return decodeOTel(line, e)
+		}
+		return decodeJSON(line, e)
+	}
+	s := string(line)
+	e.Level = LevelInfo
+	e.Time = time.Now()
+	e.Message = s
+	if l, pre, ok := textLevel(s); ok {
+		e.Level = l
+		rest := strings.TrimLeft(s[len(pre):], " ")
+		if i := strings.IndexByte(rest, ' '); i > 0 {
+			if t, err := time.Parse(time.RFC3339Nano, rest[:i]); err == nil {
+				e.Time = t
+				rest = rest[i+1:]
+			}
+		}
+		e.Message = rest
+	}
+	return nil
+}
+
+// textLevel finds the level string, default or registered, that s starts
+// with.
+func textLevel(s string) (Level, string, bool) {
+	for i, pre := range DefaultLevelStrings {
+		pre = strings.TrimRight(pre, " ")
+		if pre != "" && strings.HasPrefix(s, pre+" ") {
+			return indexLevel(i), pre, true
+		}
+	}
+	registered.RLock()
+	defer registered.RUnlock()
+	for l, name := range registered.names {
+		if name != "" && strings.HasPrefix(s, name+" ") {
+			return l, name, true
+		}
+	}
+	return 0, "", false
+}
+
+func decodeOTel(line []byte, e *Entry) error {
+	var rec otelRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+	switch n := rec.SeverityNumber; {
+	case n <= 4:
+		e.Level = LevelTrace
+	case n <= 8:
+		e.Level = LevelDebug
+	case n <= 12:
+		e.Level = LevelInfo
+	case n <= 16:
+		e.Level = LevelWarn
+	default:
+		e.Level = LevelError
+	}
+	if ns, err := strconv.ParseInt(rec.TimeUnixNano, 10, 64); err == nil {
+		e.Time = time.Unix(0, ns)
+	} else {
+		e.Time = time.Now()
+	}
+	e.Message = rec.Body.StringValue
+	if rec.TraceID != "" {
+		e.Fields = append(e.Fields, Field{Key: "trace_id", Value: rec.TraceID}, Field{Key: "span_id", Value: rec.SpanID})
+	}
+	for _, a := range rec.Attributes {
+		var v interface{}
+		switch {
+		case a.Value.StringValue != nil:
+			v = *a.Value.StringValue
+		case a.Value.IntValue != nil:
+			n, err := strconv.ParseInt(*a.Value.IntValue, 10, 64)
+			if err != nil {
+				v = *a.Value.IntValue
+			} else {
+				v = n
+			}
+		case a.Value.DoubleValue != nil:
+			v = *a.Value.DoubleValue
+		case a.Value.BoolValue != nil:
+			v = *a.Value.BoolValue
+		}
+		switch a.Key {
+		case "code.filepath":
+			e.File, _ = v.(string)
+		case "code.lineno":
+			n, _ := v.(int64)
+			e.Line = int(n)
+		case "code.function":
+			e.Func, _ = v.(string)
+		case "logger.name":
+			e.Logger, _ = v.(string)
+		default:
+			e.Fields = append(e.Fields, Field{Key: a.Key, Value: v})
+		}
+	}
+	return nil
+}
+
+// decodeJSON decodes a JSONEncoder line, keeping the order of the fields.
+func decodeJSON(line []byte, e *Entry) error {
+	d := &DefaultJSONKeys
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	e.Level = LevelInfo
+	e.Time = time.Now()
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := t.(string)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		if n, ok := v.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				v = i
+			} else {
+				v, _ = n.Float64()
+			}
+		}
+		s, _ := v.(string)
+		switch key {
+		case d.Time:
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				e.Time = t
+			}
+		case d.Level:
+			if l, err := ParseLevel(s); err == nil {
+				e.Level = l
+			}
+		case d.Message:
+			e.Message = s
+		case d.Caller:
+			if i := strings.LastIndexByte(s, ':'); i > 0 {
+				e.File = s[:i]
+				e.Line, _ = strconv.Atoi(s[i+1:])
+			}
+		case d.Func:
+			e.Func = s
+		case d.Logger:
+			e.Logger = s
+		default:
+			e.Fields = append(e.Fields, Field{Key: key, Value: v})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"strconv"
+	"time"
+)
+
+// A dedup collapses runs of identical entries. It is guarded by the root
+// logger's lock.
+type dedup struct {
+	window time.Duration
+	level  Level
+	msg    string
+	n      int // repeats suppressed
+	timer  *time.Timer
+}
+
+// SetDedup collapses runs of entries with the same level and message,
+// fields aside, logged back to back within window: the first is written
+// and the others are counted, to be summed up in a "last message repeated
+// N times" entry when another message comes along or the window closes.
+// Zero turns this off.
+func (log *Logger) SetDedup(window time.Duration) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	if r.dup != nil {
+		r.flushRepeats()
+		if r.dup.timer != nil {
+			r.dup.timer.Stop()
+		}
+		r.dup = nil
+	}
+	if window > 0 {
+		r.dup = &dedup{window: window}
+	}
+}
+
+// repeated reports whether e repeats the entry before it and counts it if
+// so. Called with log locked.
+func (log *Logger) repeated(e *Entry) bool {
+	d := log.dup
+	if d.msg != "" && e.Level == d.level && e.Message == d.msg {
+		d.n++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, func() {
+				log.Lock()
+				if !log.closed && log.dup == d {
+					log.flushRepeats()
+				}
+				log.Unlock()
+			})
+		}
+		return true
+	}
+	log.flushRepeats()
+	d.level, d.msg = e.Level, e.Message
+	return false
+}
+
+// flushRepeats writes the summary of suppressed repeats, if any, and ends
+// the run. Called with log locked.
+func (log *Logger) flushRepeats() {
+	d := log.dup
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	n := d.n
+	d.n = 0
+	d.msg = ""
+	if n == 0 {
+		return
+	}
+	e := newEntry()
+	defer e.Release()
+	e.Level = d.level
+	e.Time = log.now()
+	e.Message = "last message repeated " + strconv.Itoa(n) + " times"
+	e.Fields = []Field{{Key: "repeated", Value: n}}
+	log.write(e)
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	l.SetDedup(time.Hour)
+	l.Info("a")
+	l.With("k", 1).Info("a")
+	l.Info("a")
+	l.Warn("a")
+	l.Info("b")
+	l.Info("b")
+	l.SetDedup(0)
+	l.Info("b")
+	want := []string{
+		"a",
+		"last message repeated 2 times repeated=2",
+		"a",
+		"b",
+		"last message repeated 1 times repeated=1",
+		"b",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, " "+want[i]) {
+			t.Errorf("line %d = %q, want it to end in %q", i, line, want[i])
+		}
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	l.SetDedup(10 * time.Millisecond)
+	l.Info("a")
+	l.Info("a")
+	// The summary is written by a timer, under the logger's lock.
+	output := func() string {
+		l.Lock()
+		defer l.Unlock()
+		return buf.String()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(output(), "repeated=1") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no summary after the window:\n%s", output())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// The window closed the run, so the message is written again.
+	l.Info("a")
+	if n := strings.Count(output(), " a\n"); n != 2 {
+		t.Errorf("message written %d times, want 2:\n%s", n, output())
+	}
+}
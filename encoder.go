@@ -0,0 +1,31 @@
+package log
+
+// An Encoder serializes entries for the output. Besides the message, an
+// entry carries the level, time, caller, if the logger's flags capture it,
+// and fields. AppendTime, AppendFields, AppendJSONString and
+// AppendJSONValue help with implementing the common parts of a format.
+type Encoder interface {
+	// Encode appends the encoding of e, including any terminating
+	// newline, to buf and returns the extended buffer.
+	Encode(buf []byte, e *Entry) []byte
+}
+
+// EncoderFunc adapts a function to an Encoder.
+type EncoderFunc func(buf []byte, e *Entry) []byte
+
+// Encode implements Encoder.
+func (f EncoderFunc) Encode(buf []byte, e *Entry) []byte {
+	return f(buf, e)
+}
+
+// SetEncoder sets the encoder of the output. A nil encoder selects the
+// built-in text format. The encoder is called with the logger locked and
+// its buffer reused, so it needs no synchronization of its own. The text,
+// JSON and logfmt formats are encoded with the logger unlocked instead,
+// so that other goroutines can log meanwhile.
+func (log *Logger) SetEncoder(enc Encoder) {
+	r := log.root
+	r.Lock()
+	r.enc = enc
+	r.Unlock()
+}
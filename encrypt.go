@@ -0,0 +1,326 @@
+package log
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Frame types of the encrypted stream format. A stream is a header with
+// the salt, followed by records and, once the writer is closed, an end
+// frame. Records and end frames carry their sequence number and length,
+// authenticated along with the data, so that missing records and streams
+// cut short are noticed.
+const (
+	frameHeader = 'H'
+	frameRecord = 'R'
+	frameEnd    = 'E'
+)
+
+const (
+	saltSize   = 16
+	headerSize = 1 + saltSize
+	frameSize  = 1 + 8 + 4 // type, sequence number and length of records
+	keyInfo    = "go-log file key"
+	maxRecord  = 1 << 24
+	minKeySize = 16
+)
+
+// ErrKeySize is returned when an encryption key is too short.
+var ErrKeySize = errors.New("log: encryption key must be at least 16 bytes")
+
+// Errors wrapped by the errors of Decrypt.
+var (
+	ErrDamaged   = errors.New("log: encrypted stream damaged")
+	ErrTruncated = errors.New("log: encrypted stream not closed")
+)
+
+// An EncryptWriter encrypts every write with AES-GCM before passing it on.
+// Each stream begins with a random salt from which a per-file key is
+// derived, so one master key can safely be shared by many files.
+// Decrypt reverses the transformation.
+type EncryptWriter struct {
+	w      io.Writer
+	key    []byte
+	aead   cipher.AEAD
+	seq    uint64
+	buf    []byte
+	closed bool
+}
+
+// NewEncryptWriter creates a writer encrypting to w with the master key.
+func NewEncryptWriter(w io.Writer, key []byte) (*EncryptWriter, error) {
+	if len(key) < minKeySize {
+		return nil, ErrKeySize
+	}
+	return &EncryptWriter{w: w, key: append([]byte(nil), key...)}, nil
+}
+
+func fileCipher(key, salt []byte) (cipher.AEAD, error) {
+	k, err := hkdf.Key(sha256.New, key, salt, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func nonce(aead cipher.AEAD, seq uint64) []byte {
+	n := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(n[len(n)-8:], seq)
+	return n
+}
+
+// sealFrame appends the frame of type typ with sequence number seq and
+// plain encrypted.
+func sealFrame(buf []byte, aead cipher.AEAD, typ byte, seq uint64, plain []byte) []byte {
+	var hdr [frameSize]byte
+	hdr[0] = typ
+	binary.BigEndian.PutUint64(hdr[1:9], seq)
+	binary.BigEndian.PutUint32(hdr[9:], uint32(len(plain)+aead.Overhead()))
+	buf = append(buf, hdr[:]...)
+	return aead.Seal(buf, nonce(aead, seq), plain, hdr[:])
+}
+
+// Write encrypts p as a single record, or as several if it is larger than
+// a record may be, and writes them in one write. The cipher state only
+// advances once the write succeeds; after a failed write the next one
+// starts a new stream, so what was written before stays readable and
+// Decrypt skips what the failed write may have left.
+func (w *EncryptWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrClosed
+	}
+	w.buf = w.buf[0:0]
+	aead := w.aead
+	seq := w.seq
+	if aead == nil {
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return 0, err
+		}
+		var err error
+		if aead, err = fileCipher(w.key, salt); err != nil {
+			return 0, err
+		}
+		w.buf = append(w.buf, frameHeader)
+		w.buf = append(w.buf, salt...)
+		seq = 0
+	}
+	for rest := p; ; {
+		chunk := rest
+		if max := maxRecord - aead.Overhead(); len(chunk) > max {
+			chunk = chunk[:max]
+		}
+		w.buf = sealFrame(w.buf, aead, frameRecord, seq, chunk)
+		seq++
+		rest = rest[len(chunk):]
+		if len(rest) == 0 {
+			break
+		}
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		w.aead = nil
+		return 0, err
+	}
+	w.aead, w.seq = aead, seq
+	return len(p), nil
+}
+
+// Close ends the stream with a frame telling Decrypt that nothing was cut
+// off and closes the underlying writer.
+func (w *EncryptWriter) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	var err error
+	if w.aead != nil {
+		_, err = w.w.Write(sealFrame(w.buf[0:0], w.aead, frameEnd, w.seq, nil))
+		w.aead = nil
+	}
+	if cerr := closeOutput(w.w); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Decrypt decrypts the streams produced by EncryptWriter from src into
+// dst. Streams may be concatenated, as happens when a file is reopened for
+// appending. Damaged data, such as a record cut short by a failed write,
+// is skipped up to the next stream, and the records around it are written
+// nonetheless; the error then wraps ErrDamaged. If the last stream was not
+// ended by Close, because it is still being written or its end was cut
+// off, the error wraps ErrTruncated.
+func Decrypt(dst io.Writer, src io.Reader, key []byte) error {
+	if len(key) < minKeySize {
+		return ErrKeySize
+	}
+	r := &frameReader{r: src}
+	var aead cipher.AEAD
+	var seq uint64
+	var buf []byte
+	var errs []error
+	for {
+		b, err := r.peek(1)
+		if len(b) == 0 {
+			if err != io.EOF {
+				return errors.Join(append(errs, err)...)
+			}
+			break
+		}
+		var bad error
+		switch b[0] {
+		case frameHeader:
+			h, err := r.peek(headerSize)
+			if err != nil {
+				bad = fmt.Errorf("reading header: %w", err)
+				break
+			}
+			if aead, err = fileCipher(key, h[1:]); err != nil {
+				return err
+			}
+			seq = 0
+			r.discard(headerSize)
+			continue
+		case frameRecord, frameEnd:
+			if aead == nil {
+				bad = errors.New("record outside a stream")
+				break
+			}
+			var fseq uint64
+			var n int
+			buf, fseq, n, bad = openFrame(r, 0, aead, buf)
+			if bad != nil {
+				break
+			}
+			switch {
+			case fseq > seq:
+				errs = append(errs, fmt.Errorf("%w at offset %d: records %d to %d missing", ErrDamaged, r.off, seq, fseq-1))
+			case fseq < seq:
+				errs = append(errs, fmt.Errorf("%w at offset %d: record %d out of order", ErrDamaged, r.off, fseq))
+			}
+			seq = fseq + 1
+			r.discard(n)
+			if b[0] == frameEnd {
+				aead = nil
+				continue
+			}
+			if _, err := dst.Write(buf); err != nil {
+				return err
+			}
+			continue
+		default:
+			bad = fmt.Errorf("invalid frame type %#x", b[0])
+		}
+		off := r.off
+		skipped := r.resync(key)
+		errs = append(errs, fmt.Errorf("%w at offset %d: %v; skipped %d bytes", ErrDamaged, off, bad, skipped))
+		aead = nil
+	}
+	if aead != nil {
+		errs = append(errs, ErrTruncated)
+	}
+	return errors.Join(errs...)
+}
+
+// openFrame decrypts the record or end frame at offset off of r into dst
+// and returns the plain text, the sequence number and the size of the
+// frame.
+func openFrame(r *frameReader, off int, aead cipher.AEAD, dst []byte) ([]byte, uint64, int, error) {
+	hdr, err := r.peek(off + frameSize)
+	if err != nil {
+		return dst, 0, 0, fmt.Errorf("reading record: %w", err)
+	}
+	hdr = hdr[off:]
+	seq := binary.BigEndian.Uint64(hdr[1:9])
+	n := binary.BigEndian.Uint32(hdr[9:])
+	if n > maxRecord {
+		return dst, 0, 0, fmt.Errorf("record too large (%d bytes)", n)
+	}
+	b, err := r.peek(off + frameSize + int(n))
+	if err != nil {
+		return dst, 0, 0, fmt.Errorf("reading record: %w", err)
+	}
+	b = b[off:]
+	plain, err := aead.Open(dst[:0], nonce(aead, seq), b[frameSize:], b[:frameSize])
+	if err != nil {
+		return dst, 0, 0, fmt.Errorf("record %d: %w", seq, err)
+	}
+	return plain, seq, frameSize + int(n), nil
+}
+
+// A frameReader reads ahead in an encrypted stream, so that Decrypt can
+// look for the next stream after damage.
+type frameReader struct {
+	r   io.Reader
+	buf []byte // read and not yet discarded
+	off int64  // offset of buf in the stream
+	err error  // of the last read
+}
+
+// peek returns the next n bytes without consuming them. At the end of
+// the stream it returns io.EOF if nothing is left and
+// io.ErrUnexpectedEOF if less than n bytes are.
+func (r *frameReader) peek(n int) ([]byte, error) {
+	for len(r.buf) < n && r.err == nil {
+		if cap(r.buf)-len(r.buf) < 4096 {
+			b := make([]byte, len(r.buf), max(2*len(r.buf), n, 64<<10))
+			copy(b, r.buf)
+			r.buf = b
+		}
+		m, err := r.r.Read(r.buf[len(r.buf):cap(r.buf)])
+		r.buf = r.buf[:len(r.buf)+m]
+		r.err = err
+	}
+	switch {
+	case len(r.buf) >= n:
+		return r.buf[:n], nil
+	case r.err != io.EOF:
+		return r.buf, r.err
+	case len(r.buf) == 0:
+		return nil, io.EOF
+	}
+	return r.buf, io.ErrUnexpectedEOF
+}
+
+func (r *frameReader) discard(n int) {
+	r.buf = r.buf[n:]
+	r.off += int64(n)
+}
+
+// resync skips to the next header whose first frame decrypts with key, or
+// to the end of the stream, and returns the number of bytes skipped.
+func (r *frameReader) resync(key []byte) int64 {
+	start := r.off
+	r.discard(1)
+	for {
+		if b, _ := r.peek(1); len(b) == 0 {
+			return r.off - start
+		}
+		if i := bytes.IndexByte(r.buf, frameHeader); i < 0 {
+			r.discard(len(r.buf))
+			continue
+		} else if i > 0 {
+			r.discard(i)
+		}
+		if h, err := r.peek(headerSize); err == nil {
+			if aead, err := fileCipher(key, h[1:]); err == nil {
+				if _, _, _, err := openFrame(r, headerSize, aead, nil); err == nil {
+					return r.off - start
+				}
+			}
+		}
+		r.discard(1)
+	}
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+// flakyWriter fails the writes whose indexes are in fail, after writing
+// half of them if they are in short too. It records the length of the
+// stream after each write in ends.
+type flakyWriter struct {
+	bytes.Buffer
+	fail  map[int]bool
+	short map[int]bool
+	ends  []int
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	n := len(w.ends)
+	defer func() { w.ends = append(w.ends, w.Len()) }()
+	if w.fail[n] {
+		if w.short[n] {
+			w.Buffer.Write(p[:len(p)/2])
+		}
+		return 0, errors.New("disk full")
+	}
+	return w.Buffer.Write(p)
+}
+
+// encryptAll writes each of writes through an EncryptWriter to fw and
+// closes it if close is set.
+func encryptAll(t *testing.T, fw *flakyWriter, writes []string, close bool) {
+	t.Helper()
+	w, err := NewEncryptWriter(fw, testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range writes {
+		n, err := w.Write([]byte(s))
+		if fw.fail[i] {
+			if err == nil {
+				t.Errorf("write %d: no error", i)
+			}
+			continue
+		}
+		if err != nil || n != len(s) {
+			t.Fatalf("write %d: %d, %v", i, n, err)
+		}
+	}
+	if close {
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	big := strings.Repeat("x", maxRecord+100)
+	three := []string{"one\n", "two\n", "three\n"}
+	tests := []struct {
+		name    string
+		writes  []string
+		fail    map[int]bool
+		short   map[int]bool
+		cut     func(fw *flakyWriter) []byte // the stream as decrypted
+		want    string
+		wantErr error
+	}{
+		{"plain", []string{"one\n", "two\n"}, nil, nil, nil, "one\ntwo\n", nil},
+		{"empty", []string{""}, nil, nil, nil, "", nil},
+		{"first write fails", three, map[int]bool{0: true}, nil, nil, "two\nthree\n", nil},
+		{"middle write fails", three, map[int]bool{1: true}, nil, nil, "one\nthree\n", nil},
+		{"all but last fail", three, map[int]bool{0: true, 1: true}, nil, nil, "three\n", nil},
+		{"larger than a record", []string{big, "after\n"}, nil, nil, nil, big + "after\n", nil},
+		{"partial write", three, map[int]bool{1: true}, map[int]bool{1: true}, nil, "one\nthree\n", ErrDamaged},
+		{"partial first write", three, map[int]bool{0: true}, map[int]bool{0: true}, nil, "two\nthree\n", ErrDamaged},
+		{"end cut off", three, nil, nil, func(fw *flakyWriter) []byte {
+			return fw.Bytes()[:fw.ends[2]]
+		}, "one\ntwo\nthree\n", ErrTruncated},
+		{"record cut out", three, nil, nil, func(fw *flakyWriter) []byte {
+			b := fw.Bytes()
+			return append(append([]byte(nil), b[:fw.ends[0]]...), b[fw.ends[1]:]...)
+		}, "one\nthree\n", ErrDamaged},
+		{"garbage between", three, nil, nil, func(fw *flakyWriter) []byte {
+			b := fw.Bytes()
+			b = append(append([]byte(nil), b[:fw.ends[1]]...), append([]byte("HRE garbage"), b[fw.ends[1]:]...)...)
+			return b
+		}, "one\ntwo\n", ErrDamaged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw := &flakyWriter{fail: tt.fail, short: tt.short}
+			encryptAll(t, fw, tt.writes, true)
+			src := fw.Bytes()
+			if tt.cut != nil {
+				src = tt.cut(fw)
+			}
+			var out bytes.Buffer
+			err := Decrypt(&out, bytes.NewReader(src), testKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decrypt = %v, want %v", err, tt.wantErr)
+			}
+			if got := out.String(); got != tt.want {
+				if len(got) > 100 || len(tt.want) > 100 {
+					t.Errorf("Decrypt: got %d bytes, want %d", len(got), len(tt.want))
+				} else {
+					t.Errorf("Decrypt: got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecryptNotClosed(t *testing.T) {
+	fw := &flakyWriter{}
+	encryptAll(t, fw, []string{"one\n"}, false)
+	var out bytes.Buffer
+	if err := Decrypt(&out, &fw.Buffer, testKey); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Decrypt = %v, want ErrTruncated", err)
+	}
+	if out.String() != "one\n" {
+		t.Errorf("Decrypt: got %q", out.String())
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	fw := &flakyWriter{}
+	encryptAll(t, fw, []string{"secret\n"}, true)
+	var out bytes.Buffer
+	if err := Decrypt(&out, &fw.Buffer, []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Error("Decrypt with the wrong key succeeded")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Decrypt with the wrong key wrote %q", out.String())
+	}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An Entry is a single log message as handed to consumers.
+//
+// Entries are pooled: the logger releases an entry once the consumers it
+// was passed to have returned. A consumer that keeps an entry past that
+// point, for example to handle it asynchronously, must call Retain and
+// later Release. Consumers must not modify entries, since the same entry
+// is shared by all of them.
+type Entry struct {
+	Level   Level
+	Time    time.Time
+	File    string // empty unless caller information was requested
+	Line    int
+	Func    string  // full name of the calling function
+	PC      uintptr // program counter of the call
+	Message string
+	// Template is the format string of formatted entries.
+	Template string
+	Fields   []Field
+	// Logger is the name of the logger that made the entry, if any.
+	Logger string
+
+	refs int32
+}
+
+var entryPool = sync.Pool{New: func() interface{} { return new(Entry) }}
+
+func newEntry() *Entry {
+	e := entryPool.Get().(*Entry)
+	e.refs = 1
+	return e
+}
+
+// Retain adds a reference to e, keeping it valid until a matching Release.
+func (e *Entry) Retain() {
+	atomic.AddInt32(&e.refs, 1)
+}
+
+// Release drops a reference to e. When the last reference is gone the
+// entry is recycled and must not be used anymore.
+func (e *Entry) Release() {
+	if n := atomic.AddInt32(&e.refs, -1); n == 0 {
+		*e = Entry{}
+		entryPool.Put(e)
+	} else if n < 0 {
+		panic("log: Entry released too often")
+	}
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Err returns a field holding err under the key error.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// WithError returns a logger writing through log's output that adds err
+// to every entry under the key error.
+func (log *Logger) WithError(err error) *Logger {
+	return log.WithFields(Err(err))
+}
+
+// errorDetails returns fields with the type of each error, and the types
+// of the errors it wraps if any, added after it, as FlagErrorDetails asks.
+func errorDetails(fields []Field) []Field {
+	n := 0
+	for _, f := range fields {
+		if err, ok := f.Value.(error); ok && err != nil {
+			n++
+		}
+	}
+	if n == 0 {
+		return fields
+	}
+	out := make([]Field, 0, len(fields)+2*n)
+	for _, f := range fields {
+		out = append(out, f)
+		err, ok := f.Value.(error)
+		if !ok || err == nil {
+			continue
+		}
+		types := errorTypes(nil, err)
+		out = append(out, Field{Key: f.Key + ".type", Value: types[0]})
+		if len(types) > 1 {
+			out = append(out, Field{Key: f.Key + ".chain", Value: types[1:]})
+		}
+	}
+	return out
+}
+
+// errorTypes appends the types of err and the errors it wraps, depth
+// first.
+func errorTypes(types []string, err error) []string {
+	types = append(types, fmt.Sprintf("%T", err))
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := u.Unwrap(); e != nil {
+			types = errorTypes(types, e)
+		}
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if e != nil {
+				types = errorTypes(types, e)
+			}
+		}
+	}
+	return types
+}
+
+// An errorChain lists the messages of an error and the errors it wraps.
+type errorChain []string
+
+// MarshalJSON implements json.Marshaler.
+func (c errorChain) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string(c))
+}
+
+// Format implements fmt.Formatter, writing c as a JSON array in the text
+// format too, so messages containing spaces stay apart.
+func (c errorChain) Format(s fmt.State, verb rune) {
+	b, _ := c.MarshalJSON()
+	s.Write(b)
+}
+
+// errorChains returns fields with the values of errors wrapping others
+// replaced by their chains, as FlagErrorChain asks, copying them if any
+// is.
+func errorChains(fields []Field) []Field {
+	copied := false
+	for i, f := range fields {
+		err, ok := f.Value.(error)
+		if !ok || err == nil {
+			continue
+		}
+		msgs := errorMessages(nil, err)
+		if len(msgs) == 1 {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		fields[i].Value = errorChain(msgs)
+	}
+	return fields
+}
+
+// errorMessages appends the messages of err and the errors it wraps,
+// depth first.
+func errorMessages(msgs []string, err error) []string {
+	msgs = append(msgs, err.Error())
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := u.Unwrap(); e != nil {
+			msgs = errorMessages(msgs, e)
+		}
+	case interface{ Unwrap() []error }:
+		for _, e := range u.Unwrap() {
+			if e != nil {
+				msgs = errorMessages(msgs, e)
+			}
+		}
+	}
+	return msgs
+}
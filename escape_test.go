@@ -0,0 +1,52 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscapedEncoders(t *testing.T) {
+	forged := "bob\nERROR 12:00:00 forged\r\x1b[2J\u0085end"
+	e := &Entry{Level: LevelInfo, Message: forged, Logger: "svc\x1b", Fields: []Field{
+		{Key: "k\ney", Value: "v\x1b[31m"},
+	}}
+	syslog := &Syslog{c: SyslogConfig{AppName: "app"}, pid: "1"}
+	tests := []struct {
+		name string
+		enc  func() string
+		// lines is the number of lines the entry may take.
+		lines int
+	}{
+		{"text", func() string { return string((&TextEncoder{}).Encode(nil, e)) }, 1},
+		{"logfmt", func() string { return string((&LogfmtEncoder{}).Encode(nil, e)) }, 1},
+		{"syslog", func() string { return string(syslog.format(nil, e)) + "\n" }, 1},
+		// The pretty format keeps the message lines, indented, and
+		// writes the field on a line of its own.
+		{"pretty", func() string { return string((&PrettyEncoder{}).Encode(nil, e)) }, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.enc()
+			if n := strings.Count(out, "\n"); n != tt.lines {
+				t.Errorf("%d lines, want %d: %q", n, tt.lines, out)
+			}
+			for _, c := range "\r\x1b\u0085" {
+				if strings.ContainsRune(out, c) {
+					t.Errorf("control character %q passed through: %q", c, out)
+				}
+			}
+			for _, line := range strings.Split(out, "\n")[1:] {
+				if strings.HasPrefix(line, "ERROR") {
+					t.Errorf("forged line %q", line)
+				}
+			}
+		})
+	}
+}
+
+func TestNoEscape(t *testing.T) {
+	e := &Entry{Level: LevelInfo, Message: "a\nb"}
+	if out := string((&TextEncoder{Flags: FlagNoEscape}).Encode(nil, e)); !strings.Contains(out, "a\nb") {
+		t.Errorf("FlagNoEscape: %q", out)
+	}
+}
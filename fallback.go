@@ -0,0 +1,58 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Fallback output is limited to a burst of 10 entries, then one per second.
+const (
+	fallbackRate  = 1
+	fallbackBurst = 10
+)
+
+var fallbackOut io.Writer = os.Stderr
+
+// fallback writes the encoded entry b to standard error after writing it
+// to an output failed with err. Called with log locked.
+func (log *Logger) fallback(now time.Time, err error, b []byte) {
+	if log.fb == nil {
+		log.fb = newLimiter(fallbackRate, fallbackBurst)
+	}
+	lost := log.fb.dropped
+	if !log.fb.allow(now) {
+		return
+	}
+	log.fb.dropped = 0
+	if lost > 0 {
+		fmt.Fprintf(fallbackOut, "log: write failed: %v (%d more entries lost)\n", err, lost)
+	} else {
+		fmt.Fprintf(fallbackOut, "log: write failed: %v\n", err)
+	}
+	fallbackOut.Write(b)
+}
+
+// SetErrorHandler sets a function called with the errors of writing
+// entries to the outputs and sinks, which the logging methods do not
+// return. It is called with the logger locked and must not log through
+// it. Nil restores the default of printing the first error to standard
+// error, unless FlagFallback reports them all.
+func (log *Logger) SetErrorHandler(h func(err error)) {
+	r := log.root
+	r.Lock()
+	r.onError = h
+	r.Unlock()
+}
+
+// writeError handles err from writing an entry. Called with log locked.
+func (log *Logger) writeError(err error) {
+	switch {
+	case log.onError != nil:
+		log.onError(err)
+	case log.flag&FlagFallback == 0 && !log.errReported:
+		log.errReported = true
+		fmt.Fprintf(fallbackOut, "log: write failed: %v\n", err)
+	}
+}
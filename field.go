@@ -0,0 +1,239 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A Field is a key-value pair attached to an entry.
+type Field struct {
+	Key   string
+	Value interface{}
+
+	// Fields made by the typed constructors, such as Int, keep their
+	// value here until the entry is known to be written.
+	kind fieldKind
+	num  uint64
+	str  string
+}
+
+// F returns a field with the given key and value.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// fieldString formats a field value as text.
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+// needsQuote reports whether a text value must be quoted to be read back
+// as a single value.
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// appendEscaped appends s with control characters, C1 ones included,
+// escaped as in a Go string literal.
+func appendEscaped(buf []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= ' ' && c != 0x7f && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		r, size := rune(c), 1
+		if c >= utf8.RuneSelf {
+			r, size = utf8.DecodeRuneInString(s[i:])
+			if r >= 0xa0 || r == utf8.RuneError {
+				i += size
+				continue
+			}
+		}
+		buf = append(buf, s[start:i]...)
+		switch r {
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if r < utf8.RuneSelf {
+				buf = append(buf, '\\', 'x')
+			} else {
+				buf = append(buf, '\\', 'u', '0', '0')
+			}
+			buf = append(buf, hexDigits[r>>4], hexDigits[r&0xf])
+		}
+		i += size
+		start = i
+	}
+	return append(buf, s[start:]...)
+}
+
+// AppendFields appends fields to buf as in the text format: each preceded
+// by a space, as key=value with the value quoted where necessary and
+// control characters in the key escaped.
+func AppendFields(buf []byte, fields []Field) []byte {
+	for _, f := range fields {
+		buf = append(buf, ' ')
+		buf = appendEscaped(buf, f.Key)
+		buf = append(buf, '=')
+		s := fieldString(f.Value)
+		if needsQuote(s) {
+			buf = strconv.AppendQuote(buf, s)
+		} else {
+			buf = append(buf, s...)
+		}
+	}
+	return buf
+}
+
+// AppendJSONValue appends v to buf as a JSON value. Errors, Stringers and
+// times become strings; other values are numbers, booleans or go through
+// encoding/json.
+func AppendJSONValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return AppendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case error, fmt.Stringer, time.Time:
+		return AppendJSONString(buf, fieldString(v))
+	case json.Marshaler:
+		if b, err := v.MarshalJSON(); err == nil {
+			return append(buf, b...)
+		}
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return append(buf, b...)
+	}
+	return AppendJSONString(buf, fieldString(v))
+}
+
+func appendJSONFloat(buf []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// JSON has no representation for these.
+		return AppendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bits))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bits)
+}
+
+// With returns a logger writing through log that adds the field key=value
+// to every entry, after the fields bound to log and before those of the
+// call, as in log.With("request_id", id).Info("handled").
+func (log *Logger) With(key string, value interface{}) *Logger {
+	return log.WithFields(Field{Key: key, Value: value})
+}
+
+// WithFields is With for several fields at once.
+func (log *Logger) WithFields(fields ...Field) *Logger {
+	d := log.derive()
+	fields = boxFields(fields)
+	if d.group != "" {
+		fields = groupFields(d.group, fields)
+	}
+	d.fields = append(d.fields[:len(d.fields):len(d.fields)], fields...)
+	return d
+}
+
+// WithGroup returns a logger writing through log's output that puts the
+// fields added later, whether bound or passed to LogT and the like, in the
+// group name: their keys are prefixed by name and a dot, as in
+// http.method, after any group of log itself. Fields bound before, and
+// those the logger adds itself, are not affected.
+func (log *Logger) WithGroup(name string) *Logger {
+	d := log.derive()
+	if name != "" {
+		d.group += name + "."
+	}
+	return d
+}
+
+// ungroupFields returns fields with the prefix group removed from the keys
+// that have it, copied if any do.
+func ungroupFields(group string, fields []Field) []Field {
+	if group == "" {
+		return fields
+	}
+	var ungrouped []Field
+	for i, f := range fields {
+		if !strings.HasPrefix(f.Key, group) {
+			continue
+		}
+		if ungrouped == nil {
+			ungrouped = append([]Field(nil), fields...)
+		}
+		ungrouped[i].Key = f.Key[len(group):]
+	}
+	if ungrouped == nil {
+		return fields
+	}
+	return ungrouped
+}
+
+// groupFields returns a copy of fields with their keys prefixed by group.
+func groupFields(group string, fields []Field) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+	grouped := make([]Field, len(fields))
+	for i, f := range fields {
+		f.Key = group + f.Key
+		grouped[i] = f
+	}
+	return grouped
+}
@@ -0,0 +1,29 @@
+package log
+
+// A Filter decides whether an entry is written, after the level checks,
+// sampling and limits passed it. It must not change or keep the entry.
+type Filter func(e *Entry) bool
+
+// AddFilter adds a filter to log and the loggers derived from it. Entries
+// are written only if every filter accepts them, for example to drop the
+// warning of a noisy library:
+//
+//	log.AddFilter(func(e *log.Entry) bool {
+//		return !(e.Logger == "thirdparty" && strings.HasPrefix(e.Message, "deprecated"))
+//	})
+func (log *Logger) AddFilter(f Filter) {
+	r := log.root
+	r.Lock()
+	r.filters = append(r.filters, f)
+	r.Unlock()
+}
+
+// filtered reports whether a filter rejects e. Called with log locked.
+func (log *Logger) filtered(e *Entry) bool {
+	for _, f := range log.filters {
+		if !f(e) {
+			return true
+		}
+	}
+	return false
+}
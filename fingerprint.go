@@ -0,0 +1,23 @@
+package log
+
+import (
+	"hash/fnv"
+	"io"
+	"strconv"
+)
+
+// fingerprint identifies the log statement that produced e: its call site
+// and format string, or the message for entries without either.
+func fingerprint(e *Entry) string {
+	h := fnv.New64a()
+	if e.File != "" && e.File != "?" {
+		io.WriteString(h, e.File)
+		io.WriteString(h, strconv.Itoa(e.Line))
+		io.WriteString(h, e.Template)
+	} else if e.Template != "" {
+		io.WriteString(h, e.Template)
+	} else {
+		io.WriteString(h, e.Message)
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
@@ -0,0 +1,67 @@
+package log
+
+// A flightRecorder keeps the last entries discarded for their level.
+type flightRecorder struct {
+	min     Level
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// SetFlightRecorder makes log keep the last n entries from level min, such
+// as LevelDebug, that are discarded for being below the minimum level of
+// their logger, and write them before the next entry at the error level
+// or above, so errors come with the context leading up to them. Zero
+// disables it.
+func (log *Logger) SetFlightRecorder(n int, min Level) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	if n <= 0 {
+		r.flight.Store(nil)
+		return
+	}
+	r.flight.Store(&flightRecorder{min: min, entries: make([]Entry, n)})
+}
+
+// records reports whether entries at level l are kept when discarded.
+func (log *Logger) records(l Level) bool {
+	fr := log.root.flight.Load()
+	return fr != nil && l >= fr.min
+}
+
+// record keeps a copy of e. Called with the root locked.
+func (fr *flightRecorder) record(e *Entry) {
+	c := *e
+	c.Fields = append([]Field(nil), e.Fields...)
+	c.refs = 0
+	fr.entries[fr.next] = c
+	fr.next++
+	if fr.next == len(fr.entries) {
+		fr.next = 0
+		fr.full = true
+	}
+}
+
+// flush writes the kept entries, oldest first, through log and forgets
+// them. Called with log locked.
+func (fr *flightRecorder) flush(log *Logger) {
+	start, n := 0, fr.next
+	if fr.full {
+		start, n = fr.next, len(fr.entries)
+	}
+	for i := 0; i < n; i++ {
+		c := &fr.entries[(start+i)%len(fr.entries)]
+		e := newEntry()
+		refs := e.refs
+		*e = *c
+		e.refs = refs
+		*c = Entry{}
+		if !log.filtered(e) {
+			log.write(e)
+		}
+		e.Release()
+	}
+	fr.next = 0
+	fr.full = false
+}
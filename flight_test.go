@@ -0,0 +1,42 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	l.SetFlightRecorder(3, LevelDebug)
+	l.Trace("trace")
+	for i := 1; i <= 5; i++ {
+		l.Debugf("debug %d", i)
+	}
+	l.With("k", 1).Debug("with")
+	l.Info("info")
+	if got := buf.String(); got != "INFO  info\n" {
+		t.Fatalf("discarded entries written before an error:\n%s", got)
+	}
+	l.Error("error")
+	want := []string{"INFO  info", "DEBUG debug 4", "DEBUG debug 5", "DEBUG with k=1", "ERROR error"}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got\n%s\nwant the last 3 debug entries before the error:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The recorder starts over after writing its entries.
+	buf.Reset()
+	l.Error("again")
+	if got := buf.String(); got != "ERROR again\n" {
+		t.Errorf("entries written twice:\n%s", got)
+	}
+
+	buf.Reset()
+	l.SetFlightRecorder(0, LevelDebug)
+	l.Debug("debug")
+	l.Error("error")
+	if got := buf.String(); got != "ERROR error\n" {
+		t.Errorf("entries kept with the recorder off:\n%s", got)
+	}
+}
@@ -0,0 +1,258 @@
+package log
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FluentConfig configures a Fluent sink.
+type FluentConfig struct {
+	// Network is "tcp", the default, or "unix".
+	Network string
+	// Addr defaults to 127.0.0.1:24224.
+	Addr string
+	// Tag defaults to the program name. Entries of named loggers are
+	// tagged with the name appended, as in app.db, so they can be routed
+	// separately.
+	Tag string
+	// RequireAck waits for the server to acknowledge each entry and
+	// resends it once over a new connection if it does not.
+	RequireAck bool
+	// AckTimeout defaults to 10s.
+	AckTimeout time.Duration
+}
+
+// A Fluent is a sink sending entries to Fluentd or Fluent Bit with the
+// Forward protocol. Records hold the message as message, the level and,
+// if known, the caller and logger name, along with the fields.
+type Fluent struct {
+	mu     sync.Mutex
+	c      FluentConfig
+	conn   net.Conn
+	r      *bufio.Reader
+	buf    []byte
+	closed bool
+}
+
+var errFluentAck = errors.New("log: fluent: wrong acknowledgement")
+
+// NewFluent creates a Fluent sink and connects to the server. Lost
+// connections are reestablished on the next entry.
+func NewFluent(c FluentConfig) (*Fluent, error) {
+	if c.Network == "" {
+		c.Network = "tcp"
+	}
+	if c.Addr == "" {
+		c.Addr = "127.0.0.1:24224"
+	}
+	if c.Tag == "" {
+		c.Tag = filepath.Base(os.Args[0])
+	}
+	if c.AckTimeout <= 0 {
+		c.AckTimeout = 10 * time.Second
+	}
+	f := &Fluent{c: c}
+	if err := f.dial(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Fluent) dial() error {
+	conn, err := net.Dial(f.c.Network, f.c.Addr)
+	if err != nil {
+		return err
+	}
+	f.conn = conn
+	f.r = bufio.NewReader(conn)
+	return nil
+}
+
+// format appends e in the message mode of the protocol: an array of the
+// tag, the time, the record and, to ask for an acknowledgement, options
+// naming the chunk.
+func (f *Fluent) format(buf []byte, e *Entry, chunk string) []byte {
+	if chunk != "" {
+		buf = appendMsgpackArray(buf, 4)
+	} else {
+		buf = appendMsgpackArray(buf, 3)
+	}
+	tag := f.c.Tag
+	if e.Logger != "" {
+		tag += "." + e.Logger
+	}
+	buf = appendMsgpackString(buf, tag)
+	buf = appendMsgpackTime(buf, e.Time)
+	n := 2 + len(e.Fields)
+	if e.File != "" {
+		n++
+	}
+	if e.Func != "" {
+		n++
+	}
+	if e.Logger != "" {
+		n++
+	}
+	buf = appendMsgpackMap(buf, n)
+	buf = appendMsgpackString(buf, "level")
+	buf = appendMsgpackString(buf, e.Level.String())
+	buf = appendMsgpackString(buf, "message")
+	buf = appendMsgpackString(buf, e.Message)
+	if e.File != "" {
+		buf = appendMsgpackString(buf, "caller")
+		buf = appendMsgpackString(buf, e.File+":"+strconv.Itoa(e.Line))
+	}
+	if e.Func != "" {
+		buf = appendMsgpackString(buf, "func")
+		buf = appendMsgpackString(buf, e.Func)
+	}
+	if e.Logger != "" {
+		buf = appendMsgpackString(buf, "logger")
+		buf = appendMsgpackString(buf, e.Logger)
+	}
+	for _, fl := range e.Fields {
+		buf = appendMsgpackString(buf, fl.Key)
+		buf = appendMsgpackValue(buf, fl.Value)
+	}
+	if chunk != "" {
+		buf = appendMsgpackMap(buf, 1)
+		buf = appendMsgpackString(buf, "chunk")
+		buf = appendMsgpackString(buf, chunk)
+	}
+	return buf
+}
+
+// WriteEntry implements Sink.
+func (f *Fluent) WriteEntry(e *Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+	var chunk string
+	if f.c.RequireAck {
+		var id [16]byte
+		rand.Read(id[:])
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+	}
+	f.buf = f.format(f.buf[0:0], e, chunk)
+	if err := f.send(chunk); err != nil {
+		// The server may have restarted; try once more.
+		if f.conn != nil {
+			f.conn.Close()
+			f.conn = nil
+		}
+		return f.send(chunk)
+	}
+	return nil
+}
+
+// send writes the formatted entry and waits for the acknowledgement of
+// chunk, if any.
+func (f *Fluent) send(chunk string) error {
+	if f.conn == nil {
+		if err := f.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err := f.conn.Write(f.buf); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+	f.conn.SetReadDeadline(time.Now().Add(f.c.AckTimeout))
+	defer f.conn.SetReadDeadline(time.Time{})
+	ack, err := readFluentAck(f.r)
+	if err != nil {
+		return err
+	}
+	if ack != chunk {
+		return errFluentAck
+	}
+	return nil
+}
+
+// readFluentAck reads the response {"ack": chunk} and returns chunk.
+func readFluentAck(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b&0xf0 != 0x80 {
+		return "", errFluentAck
+	}
+	var ack string
+	for n := int(b & 0x0f); n > 0; n-- {
+		key, err := readMsgpackString(r)
+		if err != nil {
+			return "", err
+		}
+		value, err := readMsgpackString(r)
+		if err != nil {
+			return "", err
+		}
+		if key == "ack" {
+			ack = value
+		}
+	}
+	return ack, nil
+}
+
+// readMsgpackString reads a MessagePack string or binary value.
+func readMsgpackString(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	var n int
+	switch {
+	case b&0xe0 == 0xa0:
+		n = int(b & 0x1f)
+	case b == 0xd9 || b == 0xc4:
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		n = int(c)
+	case b == 0xda || b == 0xc5:
+		var l [2]byte
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			return "", err
+		}
+		n = int(binary.BigEndian.Uint16(l[:]))
+	default:
+		return "", errFluentAck
+	}
+	s := make([]byte, n)
+	if _, err := io.ReadFull(r, s); err != nil {
+		return "", err
+	}
+	return string(s), nil
+}
+
+// Close closes the connection.
+func (f *Fluent) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+	f.closed = true
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
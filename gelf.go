@@ -0,0 +1,222 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// GELFConfig configures a GELF sink.
+type GELFConfig struct {
+	// Network is "udp", the default, or "tcp".
+	Network string
+	Addr    string
+	// Host is the source reported to Graylog, the name of the host by
+	// default.
+	Host string
+	// Compress gzips UDP messages.
+	Compress bool
+	// ChunkSize is the largest UDP datagram sent, 1420 bytes by default.
+	// Longer messages are chunked.
+	ChunkSize int
+}
+
+// A GELF is a sink sending entries to Graylog in the Graylog Extended Log
+// Format 1.1. Levels map to syslog severities as with Syslog, fields to
+// additional fields with their keys prefixed by an underscore. Over TCP,
+// messages are terminated by a null byte.
+type GELF struct {
+	mu     sync.Mutex
+	c      GELFConfig
+	conn   net.Conn
+	buf    []byte
+	zbuf   bytes.Buffer
+	zw     *gzip.Writer
+	closed bool
+}
+
+// GELF chunks start with these bytes and number at most gelfMaxChunks.
+const (
+	gelfMagic     = "\x1e\x0f"
+	gelfMaxChunks = 128
+)
+
+// NewGELF creates a GELF sink and connects to the server. Lost TCP
+// connections are reestablished on the next entry.
+func NewGELF(c GELFConfig) (*GELF, error) {
+	if c.Network == "" {
+		c.Network = "udp"
+	}
+	if c.Host == "" {
+		c.Host, _ = os.Hostname()
+	}
+	if c.ChunkSize <= 12 {
+		c.ChunkSize = 1420
+	}
+	g := &GELF{c: c}
+	if err := g.dial(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *GELF) dial() error {
+	conn, err := net.Dial(g.c.Network, g.c.Addr)
+	if err != nil {
+		return err
+	}
+	g.conn = conn
+	return nil
+}
+
+// gelfKey appends key as the name of an additional field, replacing the
+// characters GELF does not allow.
+func gelfKey(buf []byte, key string) []byte {
+	buf = append(buf, `"_`...)
+	if key == "id" {
+		// _id is reserved.
+		buf = append(buf, '_')
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '.' || c == '-' || c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+			buf = append(buf, c)
+		} else {
+			buf = append(buf, '_')
+		}
+	}
+	return append(buf, `":`...)
+}
+
+func (g *GELF) format(buf []byte, e *Entry) []byte {
+	buf = append(buf, `{"version":"1.1","host":`...)
+	buf = AppendJSONString(buf, g.c.Host)
+	buf = append(buf, `,"short_message":`...)
+	buf = AppendJSONString(buf, e.Message)
+	buf = append(buf, `,"timestamp":`...)
+	ns := e.Time.UnixNano()
+	buf = strconv.AppendInt(buf, ns/1e9, 10)
+	buf = append(buf, '.')
+	buf = append(buf, strconv.FormatInt(1e6+ns%1e9/1e3, 10)[1:]...)
+	buf = append(buf, `,"level":`...)
+	buf = strconv.AppendInt(buf, int64(syslogSeverity(e.Level)), 10)
+	if e.File != "" {
+		buf = append(buf, `,"_file":`...)
+		buf = AppendJSONString(buf, e.File)
+		buf = append(buf, `,"_line":`...)
+		buf = strconv.AppendInt(buf, int64(e.Line), 10)
+	}
+	if e.Func != "" {
+		buf = append(buf, `,"_func":`...)
+		buf = AppendJSONString(buf, e.Func)
+	}
+	if e.Logger != "" {
+		buf = append(buf, `,"_logger":`...)
+		buf = AppendJSONString(buf, e.Logger)
+	}
+	for _, f := range e.Fields {
+		buf = append(buf, ',')
+		buf = gelfKey(buf, f.Key)
+		switch v := f.Value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			buf = AppendJSONValue(buf, v)
+		default:
+			// Additional fields are strings or numbers.
+			buf = AppendJSONString(buf, fieldString(v))
+		}
+	}
+	return append(buf, '}')
+}
+
+// WriteEntry implements Sink.
+func (g *GELF) WriteEntry(e *Entry) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return ErrClosed
+	}
+	g.buf = g.format(g.buf[0:0], e)
+	if g.c.Network != "udp" && g.c.Network != "udp4" && g.c.Network != "udp6" {
+		g.buf = append(g.buf, 0)
+		return g.send(g.buf)
+	}
+	msg := g.buf
+	if g.c.Compress {
+		g.zbuf.Reset()
+		if g.zw == nil {
+			g.zw = gzip.NewWriter(&g.zbuf)
+		} else {
+			g.zw.Reset(&g.zbuf)
+		}
+		g.zw.Write(msg)
+		g.zw.Close()
+		msg = g.zbuf.Bytes()
+	}
+	if len(msg) <= g.c.ChunkSize {
+		return g.send(msg)
+	}
+	return g.sendChunked(msg)
+}
+
+// sendChunked sends msg in chunks of at most ChunkSize bytes.
+func (g *GELF) sendChunked(msg []byte) error {
+	size := g.c.ChunkSize - 12
+	n := (len(msg) + size - 1) / size
+	if n > gelfMaxChunks {
+		return errors.New("log: GELF message too long")
+	}
+	id := rand.Uint64()
+	chunk := make([]byte, 0, g.c.ChunkSize)
+	for i := 0; i < n; i++ {
+		chunk = append(chunk[0:0], gelfMagic...)
+		for s := 56; s >= 0; s -= 8 {
+			chunk = append(chunk, byte(id>>s))
+		}
+		chunk = append(chunk, byte(i), byte(n))
+		chunk = append(chunk, msg[i*size:min((i+1)*size, len(msg))]...)
+		if err := g.send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GELF) send(msg []byte) error {
+	if g.conn == nil {
+		if err := g.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err := g.conn.Write(msg); err != nil {
+		// The server may have restarted; try once more.
+		g.conn.Close()
+		g.conn = nil
+		if err := g.dial(); err != nil {
+			return err
+		}
+		_, err = g.conn.Write(msg)
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (g *GELF) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return ErrClosed
+	}
+	g.closed = true
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
@@ -0,0 +1,226 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HoneycombConfig configures a Honeycomb sink.
+type HoneycombConfig struct {
+	APIKey  string
+	Dataset string
+	// APIHost defaults to https://api.honeycomb.io.
+	APIHost string
+	// SampleRate keeps one in SampleRate entries and reports the rate to
+	// Honeycomb, which weights the kept events accordingly. Zero or one
+	// keeps everything.
+	SampleRate int
+	// ExemptWarnings sends warnings and errors regardless of SampleRate.
+	ExemptWarnings bool
+	// BatchSize is the number of events sent per request, 100 by default.
+	BatchSize int
+	// Interval is the longest time events are held back, 1s by default.
+	Interval time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Honeycomb is a sink sending entries as Honeycomb events. Events are
+// batched and sent in the background.
+type Honeycomb struct {
+	c    HoneycombConfig
+	url  string
+	kick chan struct{}
+	quit chan struct{}
+	done chan struct{}
+
+	mu      sync.Mutex
+	batch   []byte
+	n       int
+	dropped int
+	err     error
+	closed  bool
+
+	sendMu sync.Mutex
+}
+
+// NewHoneycomb creates a Honeycomb sink and starts sending in the
+// background. Close stops it.
+func NewHoneycomb(c HoneycombConfig) *Honeycomb {
+	if c.APIHost == "" {
+		c.APIHost = "https://api.honeycomb.io"
+	}
+	if c.SampleRate < 1 {
+		c.SampleRate = 1
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.Interval <= 0 {
+		c.Interval = time.Second
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	h := &Honeycomb{
+		c:    c,
+		url:  c.APIHost + "/1/batch/" + url.PathEscape(c.Dataset),
+		kick: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// WriteEntry implements Sink. It reports errors of earlier sends, and
+// ErrClosed after Close.
+func (h *Honeycomb) WriteEntry(e *Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	rate := h.c.SampleRate
+	if h.c.ExemptWarnings && e.Level >= LevelWarn {
+		rate = 1
+	}
+	if rate > 1 && rand.IntN(rate) != 0 {
+		return nil
+	}
+	if h.n >= 10*h.c.BatchSize {
+		// The API is not keeping up; don't grow without bound.
+		h.dropped++
+		return h.takeErr()
+	}
+	if h.n == 0 {
+		h.batch = append(h.batch[:0], '[')
+	} else {
+		h.batch = append(h.batch, ',')
+	}
+	h.batch = append(h.batch, `{"time":`...)
+	h.batch = AppendJSONString(h.batch, e.Time.Format(time.RFC3339Nano))
+	h.batch = append(h.batch, `,"samplerate":`...)
+	h.batch = strconv.AppendInt(h.batch, int64(rate), 10)
+	h.batch = append(h.batch, `,"data":{"level":`...)
+	_, sev := otelSeverity(e.Level)
+	h.batch = AppendJSONString(h.batch, sev)
+	h.batch = append(h.batch, `,"msg":`...)
+	h.batch = AppendJSONString(h.batch, e.Message)
+	if e.File != "" {
+		h.batch = append(h.batch, `,"caller":`...)
+		h.batch = AppendJSONString(h.batch, e.File+":"+strconv.Itoa(e.Line))
+		if e.Func != "" {
+			h.batch = append(h.batch, `,"func":`...)
+			h.batch = AppendJSONString(h.batch, e.Func)
+		}
+	}
+	if e.Logger != "" {
+		h.batch = append(h.batch, `,"logger":`...)
+		h.batch = AppendJSONString(h.batch, e.Logger)
+	}
+	for _, f := range e.Fields {
+		h.batch = append(h.batch, ',')
+		h.batch = AppendJSONString(h.batch, f.Key)
+		h.batch = append(h.batch, ':')
+		h.batch = AppendJSONValue(h.batch, f.Value)
+	}
+	h.batch = append(h.batch, "}}"...)
+	h.n++
+	if h.n >= h.c.BatchSize {
+		select {
+		case h.kick <- struct{}{}:
+		default:
+		}
+	}
+	return h.takeErr()
+}
+
+// takeErr returns and clears the last send error. Called with h.mu held.
+func (h *Honeycomb) takeErr() error {
+	err := h.err
+	h.err = nil
+	if h.dropped > 0 && err == nil {
+		err = fmt.Errorf("log: honeycomb: dropped %d events", h.dropped)
+		h.dropped = 0
+	}
+	return err
+}
+
+func (h *Honeycomb) run() {
+	defer close(h.done)
+	t := time.NewTicker(h.c.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-h.kick:
+		case <-h.quit:
+			return
+		}
+		h.Flush()
+	}
+}
+
+// Flush sends all pending events.
+func (h *Honeycomb) Flush() error {
+	h.sendMu.Lock()
+	defer h.sendMu.Unlock()
+	h.mu.Lock()
+	if h.n == 0 {
+		h.mu.Unlock()
+		return nil
+	}
+	body := append(h.batch, ']')
+	h.batch = nil
+	h.n = 0
+	h.mu.Unlock()
+	err := h.send(body)
+	if err != nil {
+		h.mu.Lock()
+		h.err = err
+		h.mu.Unlock()
+	}
+	return err
+}
+
+func (h *Honeycomb) send(body []byte) error {
+	req, err := http.NewRequest("POST", h.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Honeycomb-Team", h.c.APIKey)
+	resp, err := h.c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("log: honeycomb: " + resp.Status)
+	}
+	return nil
+}
+
+// Close stops the background sender and sends the pending events.
+func (h *Honeycomb) Close() error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrClosed
+	}
+	h.closed = true
+	h.mu.Unlock()
+	close(h.quit)
+	<-h.done
+	return h.Flush()
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHoneycombClose(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Errorf("batch %q: %v", body, err)
+		}
+		mu.Lock()
+		events += len(batch)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	h := NewHoneycomb(HoneycombConfig{APIHost: srv.URL, Dataset: "test", Interval: time.Hour})
+	e := &Entry{Time: time.Now(), Level: LevelInfo, Message: "hello"}
+	if err := h.WriteEntry(e); err != nil {
+		t.Fatalf("WriteEntry: %v", err)
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := h.WriteEntry(e); !errors.Is(err, ErrClosed) {
+		t.Errorf("WriteEntry after Close = %v, want ErrClosed", err)
+	}
+	if err := h.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("second Close = %v, want ErrClosed", err)
+	}
+	if err := h.Flush(); err != nil {
+		t.Errorf("Flush after Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if events != 1 {
+		t.Errorf("sent %d events, want 1", events)
+	}
+}
@@ -0,0 +1,33 @@
+package log
+
+// A Hook is called with each entry accepted by a logger before it is
+// written, for metrics, alerting or enrichment. It may change the entry,
+// for example with AddField, but must not keep it or log itself.
+type Hook func(e *Entry)
+
+// An AfterHook is called with each entry after it was written to the
+// outputs and sinks, along with the first error doing so.
+type AfterHook func(e *Entry, err error)
+
+// AddHook adds a hook called for the entries of log and the loggers
+// derived from it, in the order the hooks were added.
+func (log *Logger) AddHook(h Hook) {
+	r := log.root
+	r.Lock()
+	r.hooks = append(r.hooks, h)
+	r.Unlock()
+}
+
+// AddAfterHook adds a hook called after entries are written.
+func (log *Logger) AddAfterHook(h AfterHook) {
+	r := log.root
+	r.Lock()
+	r.afterHooks = append(r.afterHooks, h)
+	r.Unlock()
+}
+
+// AddField appends a field to e without changing the slice it was logged
+// with.
+func (e *Entry) AddField(key string, value interface{}) {
+	e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: key, Value: value})
+}
@@ -0,0 +1,145 @@
+package log
+
+import (
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// AppendJSONString appends s to buf as a quoted JSON string. Invalid UTF-8
+// is replaced by U+FFFD.
+func AppendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if c < utf8.RuneSelf {
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `�`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// JSONKeys are the names of the keys of the core members of JSON entries.
+// Empty names select the defaults; "-" omits the member.
+type JSONKeys struct {
+	Time    string
+	Level   string
+	Message string
+	Caller  string // file:line of the call
+	Func    string // calling function, if the flags ask for it
+	Logger  string // name of the logger, if it has one
+}
+
+// DefaultJSONKeys are the default key names.
+var DefaultJSONKeys = JSONKeys{
+	Time:    "ts",
+	Level:   "level",
+	Message: "msg",
+	Caller:  "caller",
+	Func:    "func",
+	Logger:  "logger",
+}
+
+// JSONEncoder encodes entries as JSON objects, one per line. Fields follow
+// the core members under their own keys.
+type JSONEncoder struct {
+	Keys JSONKeys
+	// Flags shorten the caller as in the text format; FlagShortPath
+	// shortens the file and funcFlags select the func member.
+	Flags     Flags
+	PathDepth int // see SetPathDepth
+}
+
+// jsonKey returns the key to use, or "" if the member is omitted.
+func jsonKey(key, def string) string {
+	switch key {
+	case "":
+		return def
+	case "-":
+		return ""
+	}
+	return key
+}
+
+func appendJSONKey(buf []byte, key string) []byte {
+	if len(buf) > 0 && buf[len(buf)-1] != '{' {
+		buf = append(buf, ',')
+	}
+	buf = AppendJSONString(buf, key)
+	return append(buf, ':')
+}
+
+// Encode implements Encoder.
+func (enc *JSONEncoder) Encode(buf []byte, e *Entry) []byte {
+	k, d := &enc.Keys, &DefaultJSONKeys
+	buf = append(buf, '{')
+	if key := jsonKey(k.Time, d.Time); key != "" {
+		buf = appendJSONKey(buf, key)
+		buf = append(buf, '"')
+		buf = e.Time.AppendFormat(buf, time.RFC3339Nano)
+		buf = append(buf, '"')
+	}
+	if key := jsonKey(k.Level, d.Level); key != "" {
+		buf = appendJSONKey(buf, key)
+		buf = AppendJSONString(buf, e.Level.String())
+	}
+	if key := jsonKey(k.Logger, d.Logger); key != "" && e.Logger != "" {
+		buf = appendJSONKey(buf, key)
+		buf = AppendJSONString(buf, e.Logger)
+	}
+	if e.File != "" {
+		if key := jsonKey(k.Caller, d.Caller); key != "" {
+			file := e.File
+			if enc.Flags&FlagShortPath != 0 {
+				file = shortPath(file, enc.PathDepth)
+			}
+			buf = appendJSONKey(buf, key)
+			buf = AppendJSONString(buf, file+":"+strconv.Itoa(e.Line))
+		}
+		if key := jsonKey(k.Func, d.Func); key != "" && enc.Flags&funcFlags != 0 {
+			buf = appendJSONKey(buf, key)
+			buf = AppendJSONString(buf, funcName(e.Func, enc.Flags))
+		}
+	}
+	if key := jsonKey(k.Message, d.Message); key != "" {
+		buf = appendJSONKey(buf, key)
+		buf = AppendJSONString(buf, e.Message)
+	}
+	for _, f := range e.Fields {
+		buf = appendJSONKey(buf, f.Key)
+		buf = AppendJSONValue(buf, f.Value)
+	}
+	return append(buf, '}', '\n')
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"", `""`},
+		{"plain", `"plain"`},
+		{`say "hi"\`, `"say \"hi\"\\"`},
+		{"a\nb\rc\td", `"a\nb\rc\td"`},
+		{"\x00\x1f", `"\u0000\u001f"`},
+		{"\x7f", "\"\x7f\""},
+		{"héllo, 世界", `"héllo, 世界"`},
+		{"bad \xff byte", `"bad � byte"`},
+	} {
+		got := string(AppendJSONString([]byte("x"), tt.in))
+		if got != "x"+tt.want {
+			t.Errorf("AppendJSONString(%q) = %s, want %s", tt.in, got[1:], tt.want)
+		}
+		var s string
+		if err := json.Unmarshal([]byte(got[1:]), &s); err != nil {
+			t.Errorf("AppendJSONString(%q) = %s: %v", tt.in, got[1:], err)
+		}
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	e := &Entry{
+		Level:   LevelWarn,
+		Time:    time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC),
+		File:    "/src/app/server/main.go",
+		Line:    12,
+		Func:    "example.com/app/server.(*Server).Run",
+		Message: "slow \"request\"",
+		Logger:  "http",
+		Fields: []Field{
+			{Key: "n", Value: 3},
+			{Key: "ratio", Value: 0.5},
+			{Key: "nan", Value: math.NaN()},
+			{Key: "ok", Value: true},
+			{Key: "err", Value: errors.New("timeout")},
+			{Key: "none", Value: nil},
+			{Key: "tags", Value: []string{"a", "b"}},
+		},
+	}
+	for _, tt := range []struct {
+		name string
+		enc  JSONEncoder
+		want string
+	}{
+		{
+			"default",
+			JSONEncoder{},
+			`{"ts":"2006-01-02T15:04:05.123Z","level":"warn","logger":"http","caller":"/src/app/server/main.go:12","msg":"slow \"request\"",` +
+				`"n":3,"ratio":0.5,"nan":"NaN","ok":true,"err":"timeout","none":null,"tags":["a","b"]}` + "\n",
+		},
+		{
+			"keys",
+			JSONEncoder{Keys: JSONKeys{Time: "time", Level: "-", Message: "message", Caller: "-", Logger: "-"}},
+			`{"time":"2006-01-02T15:04:05.123Z","message":"slow \"request\"",` +
+				`"n":3,"ratio":0.5,"nan":"NaN","ok":true,"err":"timeout","none":null,"tags":["a","b"]}` + "\n",
+		},
+		{
+			"caller",
+			JSONEncoder{Keys: JSONKeys{Time: "-", Level: "-", Message: "-", Logger: "-"}, Flags: FlagShortPath | FlagShortFunc, PathDepth: 2},
+			`{"caller":"server/main.go:12","func":"server.(*Server).Run",` +
+				`"n":3,"ratio":0.5,"nan":"NaN","ok":true,"err":"timeout","none":null,"tags":["a","b"]}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(tt.enc.Encode(nil, e))
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("invalid JSON %s", got)
+			}
+		})
+	}
+}
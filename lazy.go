@@ -0,0 +1,45 @@
+package log
+
+import "fmt"
+
+// Lazy is a value computed only when an entry using it is written, for
+// arguments and field values that are expensive to produce:
+//
+//	log.Debug("state: ", log.Lazy(func() interface{} { return dump(state) }))
+//
+// As a field value it is replaced by its result before the entry is
+// encoded or passed to hooks and sinks. As an argument it is formatted as
+// its result would be.
+type Lazy func() interface{}
+
+// Format implements fmt.Formatter.
+func (f Lazy) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, fmt.FormatString(s, verb), f())
+}
+
+// String returns the result of f formatted as a field value.
+func (f Lazy) String() string {
+	return fieldString(f())
+}
+
+// resolveLazy returns fields with Lazy values replaced by their results
+// and the values of typed fields set, copying them if any is either.
+func resolveLazy(fields []Field) []Field {
+	copied := false
+	for i, f := range fields {
+		lazy, ok := f.Value.(Lazy)
+		if !ok && f.kind == kindNone {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		if ok {
+			fields[i].Value = lazy()
+		} else {
+			fields[i] = Field{Key: f.Key, Value: f.box()}
+		}
+	}
+	return fields
+}
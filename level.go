@@ -0,0 +1,198 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithMinLevel returns a logger derived from log with minimum level l, for
+// making a particular code path more or less verbose.
+func (log *Logger) WithMinLevel(l Level) *Logger {
+	d := log.derive()
+	d.min = l
+	d.local |= localMin
+	return d
+}
+
+// levelSettings are the minimum level and sampling of a root logger,
+// which the loggers derived from it share unless they set their own, so
+// that changes such as those of ApplyConfig reach loggers made before.
+type levelSettings struct {
+	min    Level
+	sample map[Level]float64
+	msgs   *msgSampler
+}
+
+// Bits of Logger.local, the settings a derived logger has of its own.
+const (
+	localMin = 1 << iota
+	localSample
+	localMsgs
+)
+
+// settings returns the level settings in effect for log. Called with log
+// locked.
+func (log *Logger) settings() levelSettings {
+	s := *log.root.shared.Load()
+	if log.local&localMin != 0 {
+		s.min = log.min
+	}
+	if log.local&localSample != 0 {
+		s.sample = log.sample
+	}
+	if log.local&localMsgs != 0 {
+		s.msgs = log.msgs
+	}
+	return s
+}
+
+// updateSettings changes the level settings of log with f: those shared
+// with the derived loggers if log is a root logger, else those of its own,
+// marked by local. Called with log locked.
+func (log *Logger) updateSettings(local int, f func(s *levelSettings)) {
+	s := log.settings()
+	f(&s)
+	if log == log.root {
+		log.shared.Store(&s)
+		return
+	}
+	log.min, log.sample, log.msgs = s.min, s.sample, s.msgs
+	log.local |= local
+}
+
+// EnableDebugFor enables debug entries for duration d on log's root logger
+// and every logger derived from it, regardless of their minimum levels,
+// for example for the duration of an incident.
+func (log *Logger) EnableDebugFor(d time.Duration) {
+	log.root.debug.Store(log.now().Add(d).UnixNano())
+}
+
+func (log *Logger) debugEnabled(l Level, now time.Time) bool {
+	return l >= LevelDebug && now.UnixNano() < log.debug.Load()
+}
+
+// registered holds the levels added with RegisterLevel.
+var registered struct {
+	sync.RWMutex
+	names map[Level]string
+}
+
+// RegisterLevel adds a level with the given name, such as
+//
+//	const LevelNotice = log.LevelInfo + 5
+//
+//	func init() { log.RegisterLevel(LevelNotice, "NOTICE") }
+//
+// Levels order by value, so LevelNotice is above info and below warn. The
+// name is the level string of the text format, padded like the default
+// ones, and in lower case the level's name in other formats and in
+// configuration. Outputs that have no notion of custom levels, such as
+// syslog, treat a registered level like the predefined one below it.
+// RegisterLevel panics if l is predefined.
+func RegisterLevel(l Level, name string) {
+	if levelIndex(l) >= 0 {
+		panic("log: RegisterLevel of predefined level " + l.String())
+	}
+	registered.Lock()
+	defer registered.Unlock()
+	if registered.names == nil {
+		registered.names = make(map[Level]string)
+	}
+	registered.names[l] = name
+}
+
+func customLevel(l Level) (string, bool) {
+	registered.RLock()
+	defer registered.RUnlock()
+	name, ok := registered.names[l]
+	return name, ok
+}
+
+// lookupLevel finds a registered level by its name in lower case.
+func lookupLevel(name string) (Level, bool) {
+	registered.RLock()
+	defer registered.RUnlock()
+	for l, n := range registered.names {
+		if strings.ToLower(n) == name {
+			return l, true
+		}
+	}
+	return 0, false
+}
+
+// customLevelString returns the level string of the text format for l.
+func customLevelString(l Level) string {
+	name, ok := customLevel(l)
+	if !ok {
+		name = "LEVEL" + strconv.Itoa(int(l))
+	}
+	for len(name) < len(DefaultLevelStrings[0]) {
+		name += " "
+	}
+	return name
+}
+
+// levelNames are the names of the predefined levels, including aliases.
+var levelNames = map[string]Level{
+	"trace":   LevelTrace,
+	"debug":   LevelDebug,
+	"info":    LevelInfo,
+	"warn":    LevelWarn,
+	"warning": LevelWarn,
+	"error":   LevelError,
+}
+
+// String returns the name of l in lower case, as in "info" or the name of
+// a registered level, or its number if it has no name.
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	if name, ok := customLevel(l); ok {
+		return strings.ToLower(name)
+	}
+	return strconv.Itoa(int(l))
+}
+
+// ParseLevel returns the level named s, ignoring case, as String names it.
+// It also accepts "warning" and numbers.
+func ParseLevel(s string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if l, ok := levelNames[name]; ok {
+		return l, nil
+	}
+	if l, ok := lookupLevel(name); ok {
+		return l, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		return Level(n), nil
+	}
+	return 0, fmt.Errorf("log: unknown level %q", s)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	v, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = v
+	return nil
+}
@@ -0,0 +1,173 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrClosed is returned when logging to a logger that was shut down.
+var ErrClosed = errors.New("log: logger is shut down")
+
+type flusher interface {
+	Flush() error
+}
+
+type syncer interface {
+	Sync() error
+}
+
+// A dropper is an output that may drop writes, such as an AsyncWriter.
+type dropper interface {
+	Dropped() uint64
+}
+
+// Own makes log responsible for closing c, typically a file or connection
+// passed to New or a sink passed to AddSink.
+func (log *Logger) Own(c io.Closer) {
+	r := log.root
+	r.Lock()
+	r.owned = append(r.owned, c)
+	r.Unlock()
+}
+
+// Shutdown stops log from accepting entries, drains and flushes its
+// output, routes and sinks, syncing files, and then closes what it owns in
+// the order it was given: what was passed to Own and the files opened by
+// Config.Build and ApplyConfig. Other outputs and sinks are left open, as
+// they may be shared. Loggers derived from log stop as well.
+//
+// If outputs such as an AsyncWriter or NetWriter dropped writes, the error
+// reports how many. If ctx ends first, Shutdown returns its error and
+// leaves the rest to finish in the background.
+func (log *Logger) Shutdown(ctx context.Context) error {
+	r := log.root
+	r.Lock()
+	if r.closed {
+		r.Unlock()
+		return ErrClosed
+	}
+	if r.dup != nil {
+		r.flushRepeats()
+	}
+	r.closed = true
+	outs := r.outputs()
+	owned := r.owned
+	if r.confOut != nil {
+		owned = append(owned, r.confOut)
+	}
+	r.owned, r.confOut = nil, nil
+	r.Unlock()
+	done := make(chan error, 1)
+	go func() {
+		var errs []error
+		for _, out := range outs {
+			errs = append(errs, flushOutput(out))
+		}
+		var dropped uint64
+		for _, out := range outs {
+			if d, ok := out.(dropper); ok {
+				dropped += d.Dropped()
+			}
+		}
+		for _, c := range owned {
+			errs = append(errs, c.Close())
+		}
+		if dropped > 0 {
+			errs = append(errs, fmt.Errorf("log: %d entries dropped", dropped))
+		}
+		done <- errors.Join(errs...)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close is Shutdown without a deadline.
+func (log *Logger) Close() error {
+	return log.Shutdown(context.Background())
+}
+
+// Flush writes out what log's output, routes and sinks buffer, such as the
+// queue of an AsyncWriter, and the summary of repeats held back by
+// SetDedup.
+func (log *Logger) Flush() error {
+	outs, err := log.root.pending()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, out := range outs {
+		if f, ok := out.(flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync is Flush followed by committing the files written to stable
+// storage, so that entries survive a crash of the machine.
+func (log *Logger) Sync() error {
+	outs, err := log.root.pending()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, out := range outs {
+		errs = append(errs, flushOutput(out))
+	}
+	return errors.Join(errs...)
+}
+
+// pending writes the summary of repeats and returns the outputs, or
+// ErrClosed after Shutdown. Called on the root logger.
+func (log *Logger) pending() ([]interface{}, error) {
+	log.Lock()
+	defer log.Unlock()
+	if log.closed {
+		return nil, ErrClosed
+	}
+	if log.dup != nil {
+		log.flushRepeats()
+	}
+	return log.outputs(), nil
+}
+
+// outputs returns the output, the writers of the routes and the sinks.
+// Called on the root logger with it locked.
+func (log *Logger) outputs() []interface{} {
+	outs := []interface{}{log.out}
+	for _, rt := range log.routes {
+		outs = append(outs, rt.W)
+	}
+	for _, s := range log.sinks {
+		outs = append(outs, s)
+	}
+	return outs
+}
+
+// flushOutput flushes out if it buffers and syncs it if it is a file.
+func flushOutput(out interface{}) error {
+	var errs []error
+	if f, ok := out.(flusher); ok {
+		errs = append(errs, f.Flush())
+	}
+	if s, ok := out.(syncer); ok && out != os.Stdout && out != os.Stderr {
+		errs = append(errs, s.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// closeOutput flushes and closes out.
+func closeOutput(out interface{}) error {
+	err := flushOutput(out)
+	if c, ok := out.(io.Closer); ok && out != os.Stdout && out != os.Stderr {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// closeRecorder is a writer recording whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// blockingWriter blocks writes until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	return len(p), nil
+}
+
+func TestShutdown(t *testing.T) {
+	tests := []struct {
+		name       string
+		own        bool
+		wantClosed bool
+	}{
+		{"owned", true, true},
+		{"not owned", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &closeRecorder{}
+			l := New(out, LevelInfo, FlagNoDate, nil)
+			if tt.own {
+				l.Own(out)
+			}
+			l.Info("x")
+			if err := l.Shutdown(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			if out.closed != tt.wantClosed {
+				t.Errorf("closed = %v, want %v", out.closed, tt.wantClosed)
+			}
+			if err := l.Output(LevelInfo, "late"); !errors.Is(err, ErrClosed) {
+				t.Errorf("Output after Shutdown = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestShutdownReportsDropped(t *testing.T) {
+	bw := &blockingWriter{release: make(chan struct{})}
+	a := NewAsyncWriter(bw, 1)
+	a.SetNonBlocking(true)
+	l := New(a, LevelInfo, FlagNoDate, nil)
+	for i := 0; i < 10; i++ {
+		l.Info("x")
+	}
+	if a.Dropped() == 0 {
+		t.Fatal("nothing dropped")
+	}
+	close(bw.release)
+	err := l.Shutdown(t.Context())
+	if err == nil || !strings.Contains(err.Error(), "entries dropped") {
+		t.Errorf("Shutdown = %v, want a report of dropped entries", err)
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// A limiter is a token bucket allowing rate events per second with bursts
+// of up to burst events. Loggers derived from one another share it, so it
+// has a lock of its own.
+type limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+	dropped uint64
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+func (lim *limiter) allow(now time.Time) bool {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	if !lim.last.IsZero() {
+		lim.tokens += now.Sub(lim.last).Seconds() * lim.rate
+		if lim.tokens > lim.burst {
+			lim.tokens = lim.burst
+		}
+	}
+	lim.last = now
+	if lim.tokens < 1 {
+		lim.dropped++
+		return false
+	}
+	lim.tokens--
+	return true
+}
+
+// droppedCount returns the number of events not allowed so far.
+func (lim *limiter) droppedCount() uint64 {
+	lim.mu.Lock()
+	defer lim.mu.Unlock()
+	return lim.dropped
+}
+
+// maxKeys bounds the number of limiters of a keyLimiter; when it is
+// reached all of them start afresh.
+const maxKeys = 10000
+
+// A keyLimiter limits entries separately for each value of a field.
+type keyLimiter struct {
+	mu    sync.Mutex
+	key   string
+	rate  float64
+	burst int
+	m     map[string]*limiter
+}
+
+// SetKeyLimit caps the entries with the field key at rate per second,
+// with bursts of up to burst entries, separately for each value of the
+// field, such as each user ID or error class. Entries without the field
+// log freely. A rate of zero removes the limit. Derived loggers share the
+// limits.
+func (log *Logger) SetKeyLimit(key string, rate float64, burst int) {
+	log.Lock()
+	defer log.Unlock()
+	if rate <= 0 {
+		log.keyLim = nil
+		return
+	}
+	log.keyLim = &keyLimiter{key: key, rate: rate, burst: burst, m: make(map[string]*limiter)}
+}
+
+// allow decides whether to keep an entry with fields.
+func (kl *keyLimiter) allow(now time.Time, fields []Field) bool {
+	for _, f := range fields {
+		if f.Key != kl.key {
+			continue
+		}
+		v := fieldString(f.Value)
+		kl.mu.Lock()
+		defer kl.mu.Unlock()
+		lim, ok := kl.m[v]
+		if !ok {
+			if len(kl.m) >= maxKeys {
+				clear(kl.m)
+			}
+			lim = newLimiter(kl.rate, kl.burst)
+			kl.m[v] = lim
+		}
+		return lim.allow(now)
+	}
+	return true
+}
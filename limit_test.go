@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyLimit(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	now := time.Now()
+	l.SetClock(func() time.Time { return now })
+	l.SetKeyLimit("user", 1, 2)
+	alice := l.With("user", "alice")
+	for i := 0; i < 5; i++ {
+		alice.Info("a")
+		l.With("user", "bob").Info("b")
+		l.Named("child").With("user", "alice").Info("a")
+		l.Info("none")
+	}
+	got := buf.String()
+	if n := strings.Count(got, " a "); n != 2 {
+		t.Errorf("%d entries of alice kept, want the burst of 2 shared by derived loggers", n)
+	}
+	if n := strings.Count(got, " b "); n != 2 {
+		t.Errorf("%d entries of bob kept, want 2 counted apart from alice", n)
+	}
+	if n := strings.Count(got, " none\n"); n != 5 {
+		t.Errorf("%d of 5 entries without the field kept", n)
+	}
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	alice.Info("a")
+	alice.Info("a")
+	if n := strings.Count(buf.String(), "\n"); n != 1 {
+		t.Errorf("%d entries kept a second later, want 1 at a rate of 1", n)
+	}
+
+	buf.Reset()
+	l.SetKeyLimit("user", 0, 0)
+	for i := 0; i < 5; i++ {
+		l.With("user", "alice").Info("a")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 5 {
+		t.Errorf("%d of 5 entries kept without a limit", n)
+	}
+}
@@ -1,25 +1,35 @@
 package log
 
 import (
-	"fmt"
 	"io"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Level is a log level.
 type Level int
 
-// Available log levels.
+// Available log levels. They are spaced apart so that levels registered
+// with RegisterLevel can go in between.
 const (
-	LevelDebug = iota
+	LevelDebug = 10 * iota
 	LevelInfo
 	LevelWarn
 	LevelError
 )
 
+// LevelTrace is for very verbose output, such as per packet or per
+// iteration, which stays filtered out when debug entries are enabled. It
+// is below the zero Level, so routes with a zero Min leave it out as well.
+const LevelTrace = -10
+
 // LevelStrings are the strings prefixed to each log message based on level.
+// The last one is that of LevelTrace; if it is empty, "TRACE" is used.
+// Registered levels use their names instead.
 type LevelStrings [5]string
 
 // DefaultLevelStrings are the default level strings.
@@ -28,6 +38,42 @@ var DefaultLevelStrings = LevelStrings{
 	"INFO ",
 	"WARN ",
 	"ERROR",
+	"TRACE",
+}
+
+// builtinLevels are the predefined levels in the order of LevelStrings.
+var builtinLevels = [len(LevelStrings{})]Level{LevelDebug, LevelInfo, LevelWarn, LevelError, LevelTrace}
+
+// levelIndex returns the index of l in LevelStrings and similar tables,
+// or -1 if l is not predefined.
+func levelIndex(l Level) int {
+	for i, b := range builtinLevels {
+		if b == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexLevel is the inverse of levelIndex.
+func indexLevel(i int) Level {
+	return builtinLevels[i]
+}
+
+// baseLevel returns the predefined level at or below l, or LevelTrace.
+func baseLevel(l Level) Level {
+	switch {
+	case l < LevelDebug:
+		return LevelTrace
+	case l < LevelInfo:
+		return LevelDebug
+	case l < LevelWarn:
+		return LevelInfo
+	case l < LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
 }
 
 // Flags represents options for the logger.
@@ -37,27 +83,209 @@ type Flags int
 const (
 	// FlagLongPath prepends the full source file path.
 	FlagLongPath = 1 << iota
-	// FlagShortPath prepends a shortened source file path.
+	// FlagShortPath prepends a shortened source file path, by default
+	// only the file name. See SetPathDepth.
 	FlagShortPath
+	// FlagFallback copies entries that could not be written to standard
+	// error, rate limited, along with the write error.
+	FlagFallback
+	// FlagLongFunc prepends the calling function with its full import
+	// path, as in github.com/user/repo/pkg.(*T).Method.
+	FlagLongFunc
+	// FlagShortFunc prepends the calling function qualified by its
+	// package name, as in pkg.(*T).Method.
+	FlagShortFunc
+	// FlagBareFunc prepends the bare calling function, as in (*T).Method.
+	FlagBareFunc
+	// FlagFingerprint adds a fingerprint field, a hash of the call site
+	// and format string that identifies the log statement regardless of
+	// its arguments, so aggregators can group occurrences across hosts.
+	FlagFingerprint
+	// FlagNoDate omits the date, leaving the time of day without zone.
+	FlagNoDate
+	// FlagNoTime omits the time of day, leaving the date. Together with
+	// FlagNoDate it omits the timestamp altogether.
+	FlagNoTime
+	// FlagCallerFields records the caller as caller.file, caller.line and
+	// caller.func fields instead of in the header, shortened as the other
+	// flags ask. This keeps it queryable with structured encoders.
+	FlagCallerFields
+	// FlagStrict panics on malformed fields, such as key-value arguments
+	// without a key or keys that cannot be encoded unambiguously, instead
+	// of logging them as well as it can. Meant for development and tests.
+	FlagStrict
+	// FlagColor colors the level in the text format written to
+	// terminals. Other outputs, and all outputs if the environment
+	// variable NO_COLOR is set, stay plain.
+	FlagColor
+	// FlagColorCaller also colors the caller, if FlagColor is set.
+	FlagColorCaller
+	// FlagStack adds the stack of the logging goroutine as a stack field
+	// to entries at or above the error level, or the level set by
+	// SetStackLevel.
+	FlagStack
+	// FlagMilliseconds adds milliseconds to the time of day.
+	FlagMilliseconds
+	// FlagMicroseconds adds microseconds to the time of day.
+	FlagMicroseconds
+	// FlagNanoseconds adds nanoseconds to the time of day.
+	FlagNanoseconds
+	// FlagUTC records times in UTC rather than the local time zone, in
+	// all formats and sinks.
+	FlagUTC
+	// FlagErrorDetails adds the type of each error field as key.type and,
+	// if it wraps others, their types as key.chain.
+	FlagErrorDetails
+	// FlagErrorChain writes error fields wrapping other errors, with %w
+	// or errors.Join, as the list of the messages of all errors in the
+	// chain, outermost first, rather than as the outer message alone.
+	FlagErrorChain
+	// FlagHostname adds the name of the host as the field hostname, so
+	// entries of several machines in one place can be told apart.
+	FlagHostname
+	// FlagPID adds the process ID as the field pid.
+	FlagPID
+	// FlagNoEscape writes messages in the text format as they are. By
+	// default newlines and other control characters in them are escaped
+	// as in Go strings, so input logged in a message cannot forge entries
+	// or move the cursor of a terminal.
+	FlagNoEscape
+)
+
+const (
+	pathFlags   = FlagLongPath | FlagShortPath
+	funcFlags   = FlagLongFunc | FlagShortFunc | FlagBareFunc
+	callerFlags = pathFlags | funcFlags
 )
 
 // A Logger is a thread safe logger with level indicators.
 type Logger struct {
 	sync.Mutex
 	out  io.Writer
-	buf  []byte
-	min  Level
+	tty  bool  // whether out is a terminal
+	min  Level // of a derived logger with localMin, see settings
 	pre  LevelStrings
 	flag Flags
+
+	root        *Logger // owner of out; the logger itself unless derived
+	prefix      string  // prepended to every message
+	name        string  // see Named
+	fields      []Field // bound by With, added to every entry
+	group       string  // prefix of the keys of later fields, see WithGroup
+	skip        int     // frames skipped in finding the caller
+	lim         *limiter
+	sample      map[Level]float64 // fraction of entries kept per level, with localSample
+	exempt      Level             // level from which sampling and limits are skipped
+	msgs        *msgSampler       // with localMsgs
+	local       int               // settings of a derived logger's own, see settings
+	keyLim      *keyLimiter
+	dup         *dedup
+	fb          *limiter // rate limit of fallback output
+	stackMin    Level    // see FlagStack
+	onError     func(err error)
+	errReported bool
+	closed      bool
+	enc         Encoder // nil for the built-in text format
+	sinks       []Sink
+	routes      []Route // outputs besides out
+	filters     []Filter
+	hooks       []Hook
+	afterHooks  []AfterHook
+	owned       []io.Closer
+	confOut     io.Closer // output opened by ApplyConfig
+	ctxKeys     []contextKey
+	redact      []RedactRule
+	metrics     *Metrics
+	span        SpanFunc
+	text        TextEncoder  // layout of the text format besides flag and pre
+	debug       atomic.Int64 // debug enabled until this Unix time in ns
+
+	nameLevels atomic.Pointer[map[string]Level] // see SetNameLevel
+	clock      atomic.Pointer[func() time.Time] // see SetClock
+	flight     atomic.Pointer[flightRecorder]   // see SetFlightRecorder
+	pkgLevels  atomic.Pointer[pkgLevels]        // see SetPackageLevel
+	shared     atomic.Pointer[levelSettings]    // see settings
 }
 
 // New creates a new logger.
 // If pre is nil it uses the default level strings.
+// If out is nil entries only go to the logger's sinks.
 func New(out io.Writer, minLevel Level, flags Flags, pre *LevelStrings) *Logger {
 	if pre == nil {
 		pre = &DefaultLevelStrings
 	}
-	return &Logger{out: out, min: minLevel, flag: flags, pre: *pre}
+	log := &Logger{out: out, flag: flags, pre: *pre, exempt: noExempt, stackMin: LevelError, tty: isTerminal(out)}
+	log.root = log
+	log.shared.Store(&levelSettings{min: minLevel})
+	return log
+}
+
+// derive returns a logger with the same settings writing through log's
+// output.
+func (log *Logger) derive() *Logger {
+	log.Lock()
+	defer log.Unlock()
+	return &Logger{root: log.root, min: log.min, prefix: log.prefix, name: log.name, fields: log.fields, group: log.group, skip: log.skip, lim: log.lim, sample: log.sample, exempt: log.exempt, msgs: log.msgs, local: log.local, keyLim: log.keyLim}
+}
+
+// SetOutput sets the output of log's root logger, for example to a file
+// after daemonizing. Nil sends entries only to the sinks. The old output
+// is not closed unless it was opened by ApplyConfig.
+func (log *Logger) SetOutput(out io.Writer) {
+	r := log.root
+	r.Lock()
+	r.out, r.tty = out, isTerminal(out)
+	old := r.confOut
+	r.confOut = nil
+	r.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
+// Flags returns the flags of log's root logger.
+func (log *Logger) Flags() Flags {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	return r.flag
+}
+
+// SetFlags sets the flags of log's root logger.
+func (log *Logger) SetFlags(flags Flags) {
+	r := log.root
+	r.Lock()
+	r.flag = flags
+	r.Unlock()
+}
+
+// SetLevelStrings sets the level strings of the text format. Nil restores
+// the default ones.
+func (log *Logger) SetLevelStrings(pre *LevelStrings) {
+	if pre == nil {
+		pre = &DefaultLevelStrings
+	}
+	r := log.root
+	r.Lock()
+	r.pre = *pre
+	r.Unlock()
+}
+
+// WithPrefix returns a logger writing through log's output that prepends
+// prefix to every message, after any prefix of log itself.
+func (log *Logger) WithPrefix(prefix string) *Logger {
+	d := log.derive()
+	d.prefix += prefix
+	return d
+}
+
+// AddCallerSkip returns a logger writing through log's output that
+// attributes entries to the caller n frames further up the stack, for
+// helpers wrapping its methods.
+func (log *Logger) AddCallerSkip(n int) *Logger {
+	d := log.derive()
+	d.skip += n
+	return d
 }
 
 func itoa(buf *[]byte, i int, wid int) {
@@ -74,140 +302,763 @@ func itoa(buf *[]byte, i int, wid int) {
 	*buf = append(*buf, b[bp:]...)
 }
 
-func (log *Logger) date(now time.Time) {
+// date appends the timestamp now, or the parts of it flag asks for.
+func date(buf *[]byte, now time.Time, flag Flags) {
+	if flag&FlagUTC != 0 {
+		now = now.UTC()
+	}
+	if flag&FlagNoDate == 0 {
+		year, month, day := now.Date()
+		itoa(buf, year, 4)
+		*buf = append(*buf, '-')
+		itoa(buf, int(month), 2)
+		*buf = append(*buf, '-')
+		itoa(buf, day, 2)
+		if flag&FlagNoTime != 0 {
+			return
+		}
+		*buf = append(*buf, 'T')
+	}
 	hour, minute, second := now.Clock()
-	year, month, day := now.Date()
-	//nsec := now.Nanosecond()
-	itoa(&log.buf, year, 4)
-	log.buf = append(log.buf, '-')
-	itoa(&log.buf, int(month), 2)
-	log.buf = append(log.buf, '-')
-	itoa(&log.buf, day, 2)
-	log.buf = append(log.buf, 'T')
-	itoa(&log.buf, hour, 2)
-	log.buf = append(log.buf, ':')
-	itoa(&log.buf, minute, 2)
-	log.buf = append(log.buf, ':')
-	itoa(&log.buf, second, 2)
-	//log.buf = append(log.buf, '.')
-	//itoa(&log.buf, nsec, 9)
+	itoa(buf, hour, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, minute, 2)
+	*buf = append(*buf, ':')
+	itoa(buf, second, 2)
+	switch {
+	case flag&FlagNanoseconds != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, now.Nanosecond(), 9)
+	case flag&FlagMicroseconds != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, now.Nanosecond()/1e3, 6)
+	case flag&FlagMilliseconds != 0:
+		*buf = append(*buf, '.')
+		itoa(buf, now.Nanosecond()/1e6, 3)
+	}
+	if flag&FlagNoDate != 0 {
+		// A bare time of day is for reading, not for parsing.
+		return
+	}
 	_, off := now.Zone()
 	if off == 0 {
-		log.buf = append(log.buf, 'Z')
+		*buf = append(*buf, 'Z')
 	} else {
 		zone := off / 60
 		absoff := off
 		if zone < 0 {
-			log.buf = append(log.buf, '-')
+			*buf = append(*buf, '-')
 			absoff = -absoff
 			zone = -zone
 		} else {
-			log.buf = append(log.buf, '+')
+			*buf = append(*buf, '+')
 		}
-		itoa(&log.buf, zone/60, 2)
-		log.buf = append(log.buf, ':')
-		itoa(&log.buf, zone%60, 2)
+		itoa(buf, zone/60, 2)
+		*buf = append(*buf, ':')
+		itoa(buf, zone%60, 2)
+	}
+}
+
+// appendTime appends t as enc is configured to.
+func (enc *TextEncoder) appendTime(buf *[]byte, t time.Time) {
+	if enc.TimeFormat == nil {
+		date(buf, t, enc.Flags)
+		return
+	}
+	if enc.Flags&FlagUTC != 0 {
+		t = t.UTC()
+	}
+	*buf = enc.TimeFormat(*buf, t)
+}
+
+// TextEncoder encodes entries in the plain text format of Logger.
+// Caller information is only available if the logger's flags request it.
+type TextEncoder struct {
+	Flags     Flags
+	Levels    *LevelStrings // nil selects DefaultLevelStrings
+	PathDepth int           // see SetPathDepth
+	// Separator separates the parts of the header; empty means a space.
+	Separator string
+	// CallerSeparator separates the caller from the message; empty means
+	// ": ".
+	CallerSeparator string
+	// LevelWidth, if positive, pads level strings with their trailing
+	// spaces removed to this width, so custom level sets line up.
+	LevelWidth int
+	// Color colors the level, the time and, with FlagColorCaller, the
+	// caller with ANSI escape sequences.
+	Color bool
+	Theme *Theme // nil selects DefaultTheme
+	// TimeFormat, if not nil, appends the time instead of the RFC 3339
+	// form chosen by the flags. FlagUTC still applies.
+	TimeFormat func(buf []byte, t time.Time) []byte
+	// Header, if not nil, appends the header in place of all of the
+	// above.
+	Header HeaderFunc
+}
+
+// A HeaderFunc appends the header of an entry of the text format, the
+// part before the message, to buf. The file is shortened as the flags ask
+// and empty, as is the line, unless they capture the caller.
+type HeaderFunc func(buf *[]byte, level Level, t time.Time, file string, line int)
+
+// Encode implements Encoder.
+func (enc *TextEncoder) Encode(buf []byte, e *Entry) []byte {
+	enc.header(&buf, e)
+	if enc.Flags&FlagNoEscape != 0 {
+		buf = append(buf, e.Message...)
+	} else {
+		buf = appendEscaped(buf, e.Message)
+	}
+	if e.Logger != "" {
+		buf = AppendFields(buf, []Field{{Key: "logger", Value: e.Logger}})
 	}
+	buf = AppendFields(buf, e.Fields)
+	return append(buf, '\n')
 }
 
-func (log *Logger) header(level Level, now time.Time, file string, line int) {
-	log.buf = append(log.buf, log.pre[int(level)]...)
-	log.buf = append(log.buf, ' ')
+func (enc *TextEncoder) header(buf *[]byte, e *Entry) {
+	if enc.Header != nil {
+		var file string
+		var line int
+		if enc.Flags&callerFlags != 0 && e.File != "" {
+			file, line = e.File, e.Line
+			if enc.Flags&FlagShortPath != 0 {
+				file = shortPath(file, enc.PathDepth)
+			}
+		}
+		t := e.Time
+		if enc.Flags&FlagUTC != 0 {
+			t = t.UTC()
+		}
+		// A copy, so that buf does not escape to the heap when no
+		// header function is set.
+		b := *buf
+		enc.Header(&b, e.Level, t, file, line)
+		*buf = b
+		return
+	}
+	pre := enc.Levels
+	if pre == nil {
+		pre = &DefaultLevelStrings
+	}
+	sep := enc.Separator
+	if sep == "" {
+		sep = " "
+	}
+	var level string
+	if i := levelIndex(e.Level); i >= 0 {
+		level = pre[i]
+		if level == "" && e.Level == LevelTrace {
+			level = DefaultLevelStrings[i]
+		}
+	} else {
+		level = customLevelString(e.Level)
+	}
+	if enc.LevelWidth > 0 {
+		level = strings.TrimRight(level, " ")
+	}
+	theme := enc.Theme
+	if theme == nil {
+		theme = &DefaultTheme
+	}
+	if enc.Color {
+		*buf = appendColored(*buf, theme.Levels[levelIndex(baseLevel(e.Level))], level)
+	} else {
+		*buf = append(*buf, level...)
+	}
+	for i := len(level); i < enc.LevelWidth; i++ {
+		*buf = append(*buf, ' ')
+	}
+	*buf = append(*buf, sep...)
 	//2006-01-02T15:04:05.999999999Z07:00
-	log.date(now)
-	log.buf = append(log.buf, ' ')
-	if log.flag&(FlagShortPath|FlagLongPath) != 0 {
-		if log.flag&(FlagShortPath) != 0 {
-			short := file
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					short = file[i+1:]
-					break
-				}
+	if enc.Flags&(FlagNoDate|FlagNoTime) != FlagNoDate|FlagNoTime {
+		if enc.Color && theme.Time != "" {
+			*buf = append(*buf, theme.Time...)
+			enc.appendTime(buf, e.Time)
+			*buf = append(*buf, colorReset...)
+		} else {
+			enc.appendTime(buf, e.Time)
+		}
+		*buf = append(*buf, sep...)
+	}
+	if enc.Flags&callerFlags == 0 || e.File == "" {
+		// The logger did not capture the caller.
+		return
+	}
+	colorCaller := enc.Color && enc.Flags&FlagColorCaller != 0 && theme.Caller != ""
+	if colorCaller {
+		*buf = append(*buf, theme.Caller...)
+	}
+	if enc.Flags&pathFlags != 0 {
+		file := e.File
+		if enc.Flags&(FlagShortPath) != 0 {
+			file = shortPath(file, enc.PathDepth)
+		}
+		*buf = append(*buf, file...)
+		*buf = append(*buf, ':')
+		itoa(buf, e.Line, -1)
+		if enc.Flags&funcFlags != 0 {
+			*buf = append(*buf, sep...)
+		}
+	}
+	if enc.Flags&funcFlags != 0 {
+		*buf = append(*buf, funcName(e.Func, enc.Flags)...)
+	}
+	if colorCaller {
+		*buf = append(*buf, colorReset...)
+	}
+	if enc.CallerSeparator == "" {
+		*buf = append(*buf, ": "...)
+	} else {
+		*buf = append(*buf, enc.CallerSeparator...)
+	}
+}
+
+// SetSeparators sets the separator between the parts of the text header
+// and the separator after the caller. Empty strings restore the defaults,
+// a space and ": ".
+func (log *Logger) SetSeparators(sep, callerSep string) {
+	r := log.root
+	r.Lock()
+	r.text.Separator = sep
+	r.text.CallerSeparator = callerSep
+	r.Unlock()
+}
+
+// SetTimeLayout makes the text format write times with the layout of
+// time.Time.Format, such as time.Stamp. Empty restores the default.
+func (log *Logger) SetTimeLayout(layout string) {
+	var f func([]byte, time.Time) []byte
+	if layout != "" {
+		f = func(buf []byte, t time.Time) []byte {
+			return t.AppendFormat(buf, layout)
+		}
+	}
+	log.SetTimeFormat(f)
+}
+
+// SetTimeFormat makes the text format write times with f. Nil restores
+// the default.
+func (log *Logger) SetTimeFormat(f func(buf []byte, t time.Time) []byte) {
+	r := log.root
+	r.Lock()
+	r.text.TimeFormat = f
+	r.Unlock()
+}
+
+// SetHeaderFunc makes the text format write headers with f, for example
+// to match the layout of another logger:
+//
+//	logger.SetHeaderFunc(func(buf *[]byte, l log.Level, t time.Time, file string, line int) {
+//		*buf = t.AppendFormat(*buf, "2006/01/02 15:04:05 ")
+//		*buf = append(*buf, "["+strings.ToUpper(l.String())+"] "...)
+//	})
+//
+// Nil restores the built-in header.
+func (log *Logger) SetHeaderFunc(f HeaderFunc) {
+	r := log.root
+	r.Lock()
+	r.text.Header = f
+	r.Unlock()
+}
+
+// SetLevelWidth pads the level strings of the text format, with trailing
+// spaces removed, to width n. Zero prints them as they are.
+func (log *Logger) SetLevelWidth(n int) {
+	r := log.root
+	r.Lock()
+	r.text.LevelWidth = n
+	r.Unlock()
+}
+
+// AppendEntry appends e to dst in the text format of a logger with the
+// default level strings and no flags.
+func AppendEntry(dst []byte, e Entry) []byte {
+	var enc TextEncoder
+	return enc.Encode(dst, &e)
+}
+
+// AppendTime appends t to dst as in the header of the text format, that is
+// RFC 3339 with second precision unless flags ask for more, or as much of
+// it as flags ask for.
+func AppendTime(dst []byte, t time.Time, flags Flags) []byte {
+	date(&dst, t, flags)
+	return dst
+}
+
+// shortPath returns the last n components of file.
+func shortPath(file string, n int) string {
+	for i := len(file) - 1; i > 0; i-- {
+		if file[i] == '/' {
+			if n--; n <= 0 {
+				return file[i+1:]
 			}
-			file = short
 		}
-		log.buf = append(log.buf, file...)
-		log.buf = append(log.buf, ':')
-		itoa(&log.buf, line, -1)
-		log.buf = append(log.buf, ": "...)
 	}
+	return file
+}
+
+// SetPathDepth sets the number of trailing path components FlagShortPath
+// keeps, so FlagShortPath with a depth of 3 prints pkg/server/handler.go
+// rather than handler.go. Depths below one keep only the file name.
+func (log *Logger) SetPathDepth(n int) {
+	r := log.root
+	r.Lock()
+	r.text.PathDepth = n
+	r.Unlock()
+}
+
+// callerFields moves the caller of e into its fields. Called with log
+// locked.
+func (log *Logger) callerFields(e *Entry) {
+	file, fn := e.File, e.Func
+	if log.flag&FlagShortPath != 0 {
+		file = shortPath(file, log.text.PathDepth)
+	}
+	if log.flag&funcFlags != 0 {
+		fn = funcName(fn, log.flag)
+	}
+	e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)],
+		Field{Key: "caller.file", Value: file},
+		Field{Key: "caller.line", Value: e.Line},
+		Field{Key: "caller.func", Value: fn})
+	e.File, e.Line, e.Func = "", 0, ""
 }
 
-// Output is the generic printing function.
+// funcName shortens the full function name fn according to flag.
+func funcName(fn string, flag Flags) string {
+	if flag&FlagLongFunc != 0 {
+		return fn
+	}
+	for i := len(fn) - 1; i > 0; i-- {
+		if fn[i] == '/' {
+			fn = fn[i+1:]
+			break
+		}
+	}
+	if flag&FlagShortFunc != 0 {
+		return fn
+	}
+	for i := 0; i < len(fn); i++ {
+		if fn[i] == '.' {
+			return fn[i+1:]
+		}
+	}
+	return fn
+}
+
+// Output is the generic printing function. It attributes the entry to the
+// caller of the function calling it, as OutputDepth(2, l, s).
 func (log *Logger) Output(l Level, s string) error {
-	now := time.Now()
+	return log.output(3, l, "", s, nil)
+}
+
+// OutputDepth is Output attributing the entry to the caller calldepth
+// frames up, where 1 is the caller of OutputDepth.
+func (log *Logger) OutputDepth(calldepth int, l Level, s string) error {
+	return log.output(calldepth+1, l, "", s, nil)
+}
+
+// output writes an entry with message s, formatted from the template tmpl
+// if that is not empty, attributing it to the caller depth frames up.
+func (log *Logger) output(depth int, l Level, tmpl, s string, fields []Field) error {
+	return log.outputAt(depth+1, 0, log.now(), l, tmpl, s, fields)
+}
+
+// outputAt is output for an entry made at now. A non-zero pc gives the
+// caller instead of depth.
+func (log *Logger) outputAt(depth int, pc uintptr, now time.Time, l Level, tmpl, s string, fields []Field) error {
 	log.Lock()
-	defer log.Unlock()
-	if l < log.min {
+	discarded := l < log.callerMinLevel(depth+1, &pc) && !log.root.debugEnabled(l, now)
+	if discarded && !log.records(l) {
+		log.Unlock()
+		return nil
+	}
+	if len(log.fields) > 0 {
+		fields = append(log.fields[:len(log.fields):len(log.fields)], fields...)
+	}
+	fields = resolveLazy(fields)
+	if !discarded && l < log.exempt && log.thinned(now, l, tmpl, s, fields) {
+		log.Unlock()
 		return nil
 	}
-	var file string
-	var line int
-	if log.flag&(FlagShortPath|FlagLongPath) != 0 {
+	prefix, name := log.prefix, log.name
+	depth += log.skip
+	log.Unlock()
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if r.flag&FlagStrict != 0 {
+		if err := checkFields(fields); err != nil {
+			panic(err)
+		}
+	}
+	if len(r.redact) > 0 {
+		fields = redactFields(r.redact, fields)
+	}
+	if r.flag&FlagErrorDetails != 0 {
+		fields = errorDetails(fields)
+	}
+	if r.flag&FlagErrorChain != 0 {
+		fields = errorChains(fields)
+	}
+	e := newEntry()
+	defer e.Release()
+	e.Level = l
+	e.Time = now
+	if r.flag&FlagUTC != 0 {
+		e.Time = now.UTC()
+	}
+	e.Message = s
+	e.Template = tmpl
+	e.Fields = fields
+	e.Logger = name
+	if prefix != "" {
+		e.Message = prefix + s
+	}
+	if r.flag&(callerFlags|FlagFingerprint|FlagCallerFields) != 0 {
+		r.Unlock()
+		pcs := [1]uintptr{pc}
+		if pc != 0 || runtime.Callers(depth+1, pcs[:]) > 0 {
+			f, _ := runtime.CallersFrames(pcs[:]).Next()
+			e.PC = pcs[0]
+			e.File = f.File
+			e.Line = f.Line
+			e.Func = f.Function
+		} else {
+			e.File = "?"
+			e.Func = "?"
+		}
+		r.Lock()
+	}
+	if r.flag&FlagStack != 0 && l >= r.stackMin && !hasField(e.Fields, "stack") {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: "stack", Value: formatStack(callers())})
+	}
+	if r.flag&FlagFingerprint != 0 {
+		e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)], Field{Key: "fingerprint", Value: fingerprint(e)})
+	}
+	if r.flag&(FlagHostname|FlagPID) != 0 {
+		processFields(e, r.flag)
+	}
+	if r.flag&FlagCallerFields != 0 && e.File != "" {
+		r.callerFields(e)
+	}
+	fr := r.flight.Load()
+	if discarded {
+		if fr != nil {
+			fr.record(e)
+		}
+		return nil
+	}
+	if r.filtered(e) {
+		return nil
+	}
+	if fr != nil && l >= LevelError {
+		fr.flush(r)
+	}
+	for _, h := range r.hooks {
+		h(e)
+	}
+	if r.dup != nil && r.repeated(e) {
+		return nil
+	}
+	bufs := getEncodings()
+	defer putEncodings(bufs)
+	if !r.encodeUnlocked(e, bufs) {
+		return ErrClosed
+	}
+	err := r.writeEncoded(e, bufs)
+	for _, h := range r.afterHooks {
+		h(e, err)
+	}
+	return err
+}
+
+// WriteEntry writes an entry made elsewhere, for example by a Receiver,
+// through log, subject to its minimum level, redaction, filters and hooks
+// but not to sampling or rate limits. It makes a Logger usable as the
+// sink of another logger, as long as that does not create a cycle. The
+// entry itself is left as it is.
+func (log *Logger) WriteEntry(e *Entry) error {
+	log.Lock()
+	skip := e.Level < log.minLevel() && !log.root.debugEnabled(e.Level, log.now())
+	log.Unlock()
+	if skip {
+		return nil
+	}
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	c := newEntry()
+	defer c.Release()
+	*c = *e
+	c.refs = 1
+	if len(r.redact) > 0 {
+		c.Fields = redactFields(r.redact, c.Fields)
+	}
+	if r.filtered(c) {
+		return nil
+	}
+	for _, h := range r.hooks {
+		h(c)
+	}
+	bufs := getEncodings()
+	defer putEncodings(bufs)
+	if !r.encodeUnlocked(c, bufs) {
+		return ErrClosed
+	}
+	err := r.writeEncoded(c, bufs)
+	for _, h := range r.afterHooks {
+		h(c, err)
+	}
+	return err
+}
+
+// write encodes e for the output and passes it to the sinks. Called with
+// log locked.
+func (log *Logger) write(e *Entry) error {
+	bufs := getEncodings()
+	defer putEncodings(bufs)
+	return log.writeEncoded(e, bufs)
+}
+
+// encodeUnlocked encodes e in the format of log for the output into bufs
+// with log unlocked, so that goroutines logging meanwhile need not wait
+// for it. Encoders other than the stateless ones of this package, and
+// header and time functions, were promised the lock and are left to
+// writeTo. Called with log locked; it reports whether log is still open.
+func (log *Logger) encodeUnlocked(e *Entry, bufs *encodings) bool {
+	if log.out == nil {
+		return true
+	}
+	color := log.tty && log.flag&FlagColor != 0 && log.enc == nil
+	switch enc := log.enc.(type) {
+	case nil:
+		if log.text.Header != nil || log.text.TimeFormat != nil {
+			return true
+		}
+		text, pre := log.text, log.pre
+		text.Flags = log.flag
+		text.Levels = &pre
+		text.Color = color
+		log.Unlock()
+		if color {
+			bufs.color = text.Encode(bufs.color, e)
+		} else {
+			bufs.plain = text.Encode(bufs.plain, e)
+		}
+	case *JSONEncoder:
+		c := *enc
+		log.Unlock()
+		bufs.plain = c.Encode(bufs.plain, e)
+	case *LogfmtEncoder:
+		c := *enc
 		log.Unlock()
-		var ok bool
-		_, file, line, ok = runtime.Caller(2)
-		if !ok {
-			file = "?"
-			line = 0
-		}
-		log.Lock()
-	}
-	log.buf = log.buf[0:0]
-	log.header(l, now, file, line)
-	log.buf = append(log.buf, s...)
-	log.buf = append(log.buf, '\n')
-	_, err := log.out.Write(log.buf)
+		bufs.plain = c.Encode(bufs.plain, e)
+	default:
+		return true
+	}
+	log.Lock()
+	return !log.closed
+}
+
+// writeEncoded is write with the encodings of e made so far in bufs.
+func (log *Logger) writeEncoded(e *Entry, bufs *encodings) error {
+	var err error
+	errs := 0
+	if log.out != nil {
+		if err = log.writeTo(log.out, log.tty, nil, e, bufs); err != nil {
+			errs++
+		}
+	}
+	for _, rt := range log.routes {
+		if e.Level < rt.Min {
+			continue
+		}
+		if werr := log.writeTo(rt.W, rt.tty, rt.Enc, e, bufs); werr != nil {
+			errs++
+			if err == nil {
+				err = werr
+			}
+		}
+	}
+	for _, sink := range log.sinks {
+		if serr := sink.WriteEntry(e); serr != nil {
+			errs++
+			if err == nil {
+				err = serr
+			}
+		}
+	}
+	if log.metrics != nil {
+		log.metrics.count(e.Level, bufs.written, errs)
+	}
+	if err != nil {
+		log.writeError(err)
+	}
 	return err
 }
 
+// writeTo writes e to w encoded with enc or, if enc is nil, in the format
+// of log, colored if w is a terminal and the flags ask for it. Encodings
+// in the format of log are kept in bufs for the other outputs.
+func (log *Logger) writeTo(w io.Writer, tty bool, enc Encoder, e *Entry, bufs *encodings) error {
+	color := tty && log.flag&FlagColor != 0
+	if a, ok := w.(*AsyncWriter); ok {
+		if n := a.dropReport(e.Time); n > 0 {
+			log.writeDropped(w, enc, color, e.Time, n)
+		}
+	}
+	var b []byte
+	switch {
+	case enc != nil:
+		bufs.route = enc.Encode(bufs.route[0:0], e)
+		b = bufs.route
+	case color && log.enc == nil:
+		if len(bufs.color) == 0 {
+			bufs.color = log.encode(bufs.color, nil, true, e)
+		}
+		b = bufs.color
+	default:
+		if len(bufs.plain) == 0 {
+			bufs.plain = log.encode(bufs.plain, nil, false, e)
+		}
+		b = bufs.plain
+	}
+	n, err := w.Write(b)
+	bufs.written += n
+	if err != nil && log.flag&FlagFallback != 0 {
+		log.fallback(log.now(), err, b)
+	}
+	return err
+}
+
+// writeDropped writes a warning about n entries dropped by w.
+func (log *Logger) writeDropped(w io.Writer, enc Encoder, color bool, now time.Time, n int) {
+	e := newEntry()
+	defer e.Release()
+	e.Level = LevelWarn
+	e.Time = now
+	e.Message = "log: dropped " + strconv.Itoa(n) + " entries"
+	e.Fields = []Field{{Key: "dropped", Value: n}}
+	w.Write(log.encode(nil, enc, color, e))
+}
+
+// encode appends e encoded with enc or, if enc is nil, in the format of
+// log.
+func (log *Logger) encode(buf []byte, enc Encoder, color bool, e *Entry) []byte {
+	if enc == nil {
+		enc = log.enc
+	}
+	if enc != nil {
+		return enc.Encode(buf, e)
+	}
+	text := log.text
+	text.Flags = log.flag
+	text.Levels = &log.pre
+	text.Color = color
+	return text.Encode(buf, e)
+}
+
 // Log outputs a log message at the specified level.
 func (log *Logger) Log(l Level, v ...interface{}) {
-	log.Output(l, fmt.Sprint(v...))
+	if log.discards(l) {
+		return
+	}
+	log.Output(l, sprint(v))
 }
 
 // Logf outputs a formatted log message at the specified level.
 func (log *Logger) Logf(l Level, format string, v ...interface{}) {
-	log.Output(l, fmt.Sprintf(format, v...))
+	if log.discards(l) {
+		return
+	}
+	log.output(2, l, format, sprintf(format, v), nil)
+}
+
+// Trace is Log at the trace log level.
+func (log *Logger) Trace(v ...interface{}) {
+	if log.discards(LevelTrace) {
+		return
+	}
+	log.Output(LevelTrace, sprint(v))
+}
+
+// Tracef is Log at the trace log level.
+func (log *Logger) Tracef(format string, v ...interface{}) {
+	if log.discards(LevelTrace) {
+		return
+	}
+	log.output(2, LevelTrace, format, sprintf(format, v), nil)
 }
 
 // Debug is Log at the debug log level.
 func (log *Logger) Debug(v ...interface{}) {
-	log.Output(LevelDebug, fmt.Sprint(v...))
+	if log.discards(LevelDebug) {
+		return
+	}
+	log.Output(LevelDebug, sprint(v))
 }
 
 // Debugf is Log at the debug log level.
 func (log *Logger) Debugf(format string, v ...interface{}) {
-	log.Output(LevelDebug, fmt.Sprintf(format, v...))
+	if log.discards(LevelDebug) {
+		return
+	}
+	log.output(2, LevelDebug, format, sprintf(format, v), nil)
 }
 
 // Info is Log at the info log level.
 func (log *Logger) Info(v ...interface{}) {
-	log.Output(LevelInfo, fmt.Sprint(v...))
+	if log.discards(LevelInfo) {
+		return
+	}
+	log.Output(LevelInfo, sprint(v))
 }
 
 // Infof is Log at the info log level.
 func (log *Logger) Infof(format string, v ...interface{}) {
-	log.Output(LevelInfo, fmt.Sprintf(format, v...))
+	if log.discards(LevelInfo) {
+		return
+	}
+	log.output(2, LevelInfo, format, sprintf(format, v), nil)
 }
 
 // Warn is Log at the warn log level.
 func (log *Logger) Warn(v ...interface{}) {
-	log.Output(LevelWarn, fmt.Sprint(v...))
+	if log.discards(LevelWarn) {
+		return
+	}
+	log.Output(LevelWarn, sprint(v))
 }
 
 // Warnf is Log at the warn log level.
 func (log *Logger) Warnf(format string, v ...interface{}) {
-	log.Output(LevelWarn, fmt.Sprintf(format, v...))
+	if log.discards(LevelWarn) {
+		return
+	}
+	log.output(2, LevelWarn, format, sprintf(format, v), nil)
 }
 
 // Error is Log at the error log level.
 func (log *Logger) Error(v ...interface{}) {
-	log.Output(LevelError, fmt.Sprint(v...))
+	if log.discards(LevelError) {
+		return
+	}
+	log.Output(LevelError, sprint(v))
 }
 
 // Errorf is Log at the error log level.
 func (log *Logger) Errorf(format string, v ...interface{}) {
-	log.Output(LevelError, fmt.Sprintf(format, v...))
+	if log.discards(LevelError) {
+		return
+	}
+	log.output(2, LevelError, format, sprintf(format, v), nil)
 }
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"strconv"
+	"time"
+)
+
+// LogfmtEncoder encodes entries as logfmt lines, as in
+//
+//	level=info ts=2006-01-02T15:04:05.999Z caller=main.go:12 msg="started up" port=8080
+//
+// Keys and caller shortening work as for JSONEncoder.
+type LogfmtEncoder struct {
+	Keys      JSONKeys
+	Flags     Flags
+	PathDepth int // see SetPathDepth
+}
+
+// appendLogfmt appends key=value, separated by a space from anything
+// after start.
+func appendLogfmt(buf []byte, start int, key, value string) []byte {
+	if len(buf) > start {
+		buf = append(buf, ' ')
+	}
+	buf = appendEscaped(buf, key)
+	buf = append(buf, '=')
+	if needsQuote(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
+
+// Encode implements Encoder.
+func (enc *LogfmtEncoder) Encode(buf []byte, e *Entry) []byte {
+	k, d := &enc.Keys, &DefaultJSONKeys
+	start := len(buf)
+	if key := jsonKey(k.Level, d.Level); key != "" {
+		buf = appendLogfmt(buf, start, key, e.Level.String())
+	}
+	if key := jsonKey(k.Time, d.Time); key != "" {
+		buf = appendLogfmt(buf, start, key, e.Time.Format(time.RFC3339Nano))
+	}
+	if key := jsonKey(k.Logger, d.Logger); key != "" && e.Logger != "" {
+		buf = appendLogfmt(buf, start, key, e.Logger)
+	}
+	if e.File != "" {
+		if key := jsonKey(k.Caller, d.Caller); key != "" {
+			file := e.File
+			if enc.Flags&FlagShortPath != 0 {
+				file = shortPath(file, enc.PathDepth)
+			}
+			buf = appendLogfmt(buf, start, key, file+":"+strconv.Itoa(e.Line))
+		}
+		if key := jsonKey(k.Func, d.Func); key != "" && enc.Flags&funcFlags != 0 {
+			buf = appendLogfmt(buf, start, key, funcName(e.Func, enc.Flags))
+		}
+	}
+	if key := jsonKey(k.Message, d.Message); key != "" {
+		buf = appendLogfmt(buf, start, key, e.Message)
+	}
+	for _, f := range e.Fields {
+		buf = appendLogfmt(buf, start, f.Key, fieldString(f.Value))
+	}
+	return append(buf, '\n')
+}
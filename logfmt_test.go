@@ -0,0 +1,64 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLogfmtEncoder(t *testing.T) {
+	e := &Entry{
+		Level:   LevelInfo,
+		Time:    time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+		File:    "/src/app/main.go",
+		Line:    12,
+		Func:    "example.com/app.main",
+		Message: "started up",
+		Fields: []Field{
+			{Key: "port", Value: 8080},
+			{Key: "path", Value: "/var/log"},
+			{Key: "empty", Value: ""},
+			{Key: "quote", Value: `say "hi"`},
+			{Key: "eq", Value: "a=b"},
+			{Key: "line", Value: "a\nb"},
+			{Key: "err", Value: errors.New("no such file")},
+		},
+	}
+	fields := ` port=8080 path=/var/log empty="" quote="say \"hi\"" eq="a=b" line="a\nb" err="no such file"` + "\n"
+	for _, tt := range []struct {
+		name string
+		enc  LogfmtEncoder
+		want string
+	}{
+		{
+			"default",
+			LogfmtEncoder{},
+			`level=info ts=2006-01-02T15:04:05Z caller=/src/app/main.go:12 msg="started up"` + fields,
+		},
+		{
+			"keys",
+			LogfmtEncoder{Keys: JSONKeys{Level: "lvl", Time: "-", Caller: "-", Message: "message"}},
+			`lvl=info message="started up"` + fields,
+		},
+		{
+			"caller",
+			LogfmtEncoder{Keys: JSONKeys{Level: "-", Time: "-", Message: "-"}, Flags: FlagShortPath | FlagBareFunc},
+			`caller=main.go:12 func=main` + fields,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.enc.Encode(nil, e)); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	// Encode appends to buf, spacing pairs from where the entry starts.
+	named := *e
+	named.Logger = "db"
+	named.Fields = nil
+	enc := LogfmtEncoder{Keys: JSONKeys{Time: "-", Caller: "-"}}
+	if got, want := string(enc.Encode([]byte("prefix "), &named)), "prefix level=info logger=db msg=\"started up\"\n"; got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+// Package loggrpc provides gRPC interceptors writing an access log entry
+// for each call through a log.Logger.
+//
+// Entries carry the fields grpc.method, grpc.code, duration and, for
+// servers, peer.address. The message names the kind of call. Entries are
+// logged at the level given by CodeLevel for the status code.
+package loggrpc
+
+import (
+	"context"
+	"time"
+
+	log "github.com/lucy/go-log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// A Decider reports whether a call to the full method name, as
+// in /pkg.Service/Method, that ended with err is logged. A nil Decider
+// logs every call.
+type Decider func(fullMethod string, err error) bool
+
+// CodeLevel returns the level calls ending with code are logged at: info
+// for OK, warn for errors usually caused by the client and error for the
+// rest.
+func CodeLevel(code codes.Code) log.Level {
+	switch code {
+	case codes.OK:
+		return log.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.OutOfRange, codes.ResourceExhausted, codes.Aborted:
+		return log.LevelWarn
+	default:
+		return log.LevelError
+	}
+}
+
+func logCall(ctx context.Context, l *log.Logger, decide Decider, msg, method string, start time.Time, err error, server bool) {
+	if decide != nil && !decide(method, err) {
+		return
+	}
+	code := status.Code(err)
+	fields := []log.Field{
+		{Key: "grpc.method", Value: method},
+		{Key: "grpc.code", Value: code.String()},
+		{Key: "duration", Value: time.Since(start)},
+	}
+	if server {
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			fields = append(fields, log.Field{Key: "peer.address", Value: p.Addr.String()})
+		}
+	}
+	if err != nil {
+		fields = append(fields, log.Field{Key: "error", Value: err})
+	}
+	l.WithFields(fields...).LogContext(ctx, CodeLevel(code), msg)
+}
+
+// UnaryServerInterceptor returns an interceptor logging unary calls to a
+// server.
+func UnaryServerInterceptor(l *log.Logger, decide Decider) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, l, decide, "grpc: unary call", info.FullMethod, start, err, true)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging streaming calls
+// to a server once they end.
+func StreamServerInterceptor(l *log.Logger, decide Decider) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), l, decide, "grpc: stream call", info.FullMethod, start, err, true)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor logging unary calls made
+// by a client.
+func UnaryClientInterceptor(l *log.Logger, decide Decider) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(ctx, l, decide, "grpc: unary request", method, start, err, false)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor logging streaming calls
+// made by a client. Since the client owns the stream afterwards, the entry
+// is written once the stream is established, or failed to be.
+func StreamClientInterceptor(l *log.Logger, decide Decider) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		logCall(ctx, l, decide, "grpc: stream request", method, start, err, false)
+		return cs, err
+	}
+}
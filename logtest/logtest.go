@@ -0,0 +1,105 @@
+// Package logtest records the entries of a logger in memory, so tests can
+// check what was logged without parsing encoded output.
+package logtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/lucy/go-log"
+)
+
+// A Recorder is a sink keeping copies of the entries written to it.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []log.Entry
+}
+
+// New creates a logger with the minimum level min recording its entries,
+// including callers, in the returned recorder and writing nothing
+// elsewhere.
+func New(min log.Level) (*log.Logger, *Recorder) {
+	r := new(Recorder)
+	l := log.New(nil, min, log.FlagLongPath|log.FlagLongFunc, nil)
+	l.AddSink(r)
+	return l, r
+}
+
+// WriteEntry implements log.Sink.
+func (r *Recorder) WriteEntry(e *log.Entry) error {
+	c := *e
+	c.Fields = append([]log.Field(nil), e.Fields...)
+	r.mu.Lock()
+	r.entries = append(r.entries, c)
+	r.mu.Unlock()
+	return nil
+}
+
+// Entries returns the entries recorded so far, oldest first.
+func (r *Recorder) Entries() []log.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]log.Entry(nil), r.entries...)
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Contains reports whether an entry at level l with a message containing
+// substr was recorded.
+func (r *Recorder) Contains(l log.Level, substr string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.Level == l && strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field returns the value of the field key of the last recorded entry
+// having it.
+func (r *Recorder) Field(key string) (interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		for _, f := range r.entries[i].Fields {
+			if f.Key == key {
+				return f.Value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// AssertContains fails t unless Contains(l, substr), listing the recorded
+// entries.
+func (r *Recorder) AssertContains(t testing.TB, l log.Level, substr string) {
+	t.Helper()
+	if !r.Contains(l, substr) {
+		t.Errorf("no %s entry containing %q in:\n%s", l, substr, r.dump())
+	}
+}
+
+// AssertEmpty fails t if any entry was recorded.
+func (r *Recorder) AssertEmpty(t testing.TB) {
+	t.Helper()
+	if s := r.dump(); s != "" {
+		t.Errorf("unexpected entries:\n%s", s)
+	}
+}
+
+// dump formats the recorded entries in the text format.
+func (r *Recorder) dump() string {
+	var b []byte
+	for _, e := range r.Entries() {
+		b = log.AppendEntry(b, e)
+	}
+	return string(b)
+}
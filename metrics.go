@@ -0,0 +1,102 @@
+package log
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Metrics counts the entries a logger writes, for monitoring as
+// Prometheus counters. It serves them over HTTP in the Prometheus text
+// format, so it can be mounted next to or instead of other metrics:
+//
+//	m := log.NewMetrics("")
+//	logger.SetMetrics(m)
+//	http.Handle("/metrics/log", m)
+type Metrics struct {
+	ns string
+
+	mu      sync.Mutex
+	entries map[Level]uint64
+	bytes   uint64
+	errors  uint64
+}
+
+// NewMetrics creates counters named with the namespace ns, "log" by
+// default, as in log_entries_total.
+func NewMetrics(ns string) *Metrics {
+	if ns == "" {
+		ns = "log"
+	}
+	return &Metrics{ns: ns, entries: make(map[Level]uint64)}
+}
+
+// SetMetrics makes log and the loggers derived from it count the entries
+// they write in m, which may be shared by several loggers. Nil disables
+// counting.
+func (log *Logger) SetMetrics(m *Metrics) {
+	r := log.root
+	r.Lock()
+	r.metrics = m
+	r.Unlock()
+}
+
+// count records an entry at level l of which n bytes were written to the
+// outputs, with errs failed writes.
+func (m *Metrics) count(l Level, n, errs int) {
+	m.mu.Lock()
+	m.entries[l]++
+	m.bytes += uint64(n)
+	m.errors += uint64(errs)
+	m.mu.Unlock()
+}
+
+// ServeHTTP writes the counters entries_total by level, bytes_written_total
+// and write_errors_total.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	levels := make([]Level, 0, len(m.entries))
+	for l := range m.entries {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	var b []byte
+	b = append(b, "# HELP "+m.ns+"_entries_total Entries written, by level.\n"...)
+	b = append(b, "# TYPE "+m.ns+"_entries_total counter\n"...)
+	for _, l := range levels {
+		b = append(b, m.ns+`_entries_total{level="`...)
+		b = appendLabelValue(b, l.String())
+		b = append(b, `"} `...)
+		b = strconv.AppendUint(b, m.entries[l], 10)
+		b = append(b, '\n')
+	}
+	b = append(b, "# HELP "+m.ns+"_bytes_written_total Bytes written to outputs.\n"...)
+	b = append(b, "# TYPE "+m.ns+"_bytes_written_total counter\n"...)
+	b = append(b, m.ns+"_bytes_written_total "...)
+	b = strconv.AppendUint(b, m.bytes, 10)
+	b = append(b, "\n# HELP "+m.ns+"_write_errors_total Failed writes to outputs and sinks.\n"...)
+	b = append(b, "# TYPE "+m.ns+"_write_errors_total counter\n"...)
+	b = append(b, m.ns+"_write_errors_total "...)
+	b = strconv.AppendUint(b, m.errors, 10)
+	b = append(b, '\n')
+	m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(b)
+}
+
+// appendLabelValue appends a label value escaped as the Prometheus text
+// format requires, since registered level names can be anything.
+func appendLabelValue(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '"':
+			b = append(b, '\\', c)
+		case '\n':
+			b = append(b, '\\', 'n')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	const odd = LevelInfo + 7
+	RegisterLevel(odd, "A\"B\\C\nD")
+	defer func() {
+		registered.Lock()
+		delete(registered.names, odd)
+		registered.Unlock()
+	}()
+
+	m := NewMetrics("")
+	m.count(LevelInfo, 10, 0)
+	m.count(odd, 5, 1)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	got := rec.Body.String()
+	for _, want := range []string{
+		`log_entries_total{level="info"} 1` + "\n",
+		`log_entries_total{level="a\"b\\c\nd"} 1` + "\n",
+		"log_bytes_written_total 15\n",
+		"log_write_errors_total 1\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("metrics lack %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package log
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Appenders of the MessagePack encoding.
+
+func appendMsgpackUint(buf []byte, v uint64) []byte {
+	switch {
+	case v < 1<<7:
+		return append(buf, byte(v))
+	case v < 1<<8:
+		return append(buf, 0xcc, byte(v))
+	case v < 1<<16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(v))
+	case v < 1<<32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), v)
+	}
+}
+
+func appendMsgpackInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendMsgpackUint(buf, uint64(v))
+	case v >= -32:
+		return append(buf, byte(v))
+	case v >= math.MinInt8:
+		return append(buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(v))
+	}
+}
+
+func appendMsgpackString(buf []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n < 1<<8:
+		buf = append(buf, 0xd9, byte(n))
+	case n < 1<<16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+func appendMsgpackMap(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n < 1<<16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdf), uint32(n))
+	}
+}
+
+func appendMsgpackArray(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n < 1<<16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+	}
+}
+
+// appendMsgpackValue appends v, keeping nil, booleans and numbers typed
+// and encoding the rest as strings.
+func appendMsgpackValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xc0)
+	case bool:
+		if v {
+			return append(buf, 0xc3)
+		}
+		return append(buf, 0xc2)
+	case int:
+		return appendMsgpackInt(buf, int64(v))
+	case int8:
+		return appendMsgpackInt(buf, int64(v))
+	case int16:
+		return appendMsgpackInt(buf, int64(v))
+	case int32:
+		return appendMsgpackInt(buf, int64(v))
+	case int64:
+		return appendMsgpackInt(buf, v)
+	case uint:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint8:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint16:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint32:
+		return appendMsgpackUint(buf, uint64(v))
+	case uint64:
+		return appendMsgpackUint(buf, v)
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(v))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(v))
+	case string:
+		return appendMsgpackString(buf, v)
+	}
+	return appendMsgpackString(buf, fieldString(v))
+}
+
+// appendMsgpackTime appends t as the Fluentd EventTime extension, type 0
+// with seconds and nanoseconds.
+func appendMsgpackTime(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xd7, 0x00)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+}
+
+// appendMsgpackTimestamp appends t as the timestamp extension, type -1, in
+// its 96 bit form.
+func appendMsgpackTimestamp(buf []byte, t time.Time) []byte {
+	buf = append(buf, 0xc7, 12, 0xff)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(buf, uint64(t.Unix()))
+}
+
+// MsgpackEncoder encodes entries as MessagePack maps with the members of
+// JSONEncoder, for consumers that can do without text. Times use the
+// timestamp extension; numbers and booleans stay typed.
+type MsgpackEncoder struct {
+	Keys      JSONKeys
+	Flags     Flags // as in JSONEncoder
+	PathDepth int   // see SetPathDepth
+}
+
+// Encode implements Encoder.
+func (enc *MsgpackEncoder) Encode(buf []byte, e *Entry) []byte {
+	k, d := &enc.Keys, &DefaultJSONKeys
+	timeKey := jsonKey(k.Time, d.Time)
+	levelKey := jsonKey(k.Level, d.Level)
+	loggerKey := jsonKey(k.Logger, d.Logger)
+	msgKey := jsonKey(k.Message, d.Message)
+	var callerKey, funcKey string
+	if e.File != "" {
+		callerKey = jsonKey(k.Caller, d.Caller)
+		if enc.Flags&funcFlags != 0 {
+			funcKey = jsonKey(k.Func, d.Func)
+		}
+	}
+	if e.Logger == "" {
+		loggerKey = ""
+	}
+	n := len(e.Fields)
+	for _, key := range [...]string{timeKey, levelKey, loggerKey, callerKey, funcKey, msgKey} {
+		if key != "" {
+			n++
+		}
+	}
+	buf = appendMsgpackMap(buf, n)
+	if timeKey != "" {
+		buf = appendMsgpackString(buf, timeKey)
+		buf = appendMsgpackTimestamp(buf, e.Time)
+	}
+	if levelKey != "" {
+		buf = appendMsgpackString(buf, levelKey)
+		buf = appendMsgpackString(buf, e.Level.String())
+	}
+	if loggerKey != "" {
+		buf = appendMsgpackString(buf, loggerKey)
+		buf = appendMsgpackString(buf, e.Logger)
+	}
+	if callerKey != "" {
+		file := e.File
+		if enc.Flags&FlagShortPath != 0 {
+			file = shortPath(file, enc.PathDepth)
+		}
+		buf = appendMsgpackString(buf, callerKey)
+		buf = appendMsgpackString(buf, file+":"+strconv.Itoa(e.Line))
+	}
+	if funcKey != "" {
+		buf = appendMsgpackString(buf, funcKey)
+		buf = appendMsgpackString(buf, funcName(e.Func, enc.Flags))
+	}
+	if msgKey != "" {
+		buf = appendMsgpackString(buf, msgKey)
+		buf = appendMsgpackString(buf, e.Message)
+	}
+	for _, f := range e.Fields {
+		buf = appendMsgpackString(buf, f.Key)
+		buf = appendMsgpackValue(buf, f.Value)
+	}
+	return buf
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestMsgpackInt(t *testing.T) {
+	for _, tt := range []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0xcc, 0x80}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{math.MinInt8, []byte{0xd0, 0x80}},
+		{math.MinInt8 - 1, []byte{0xd1, 0xff, 0x7f}},
+		{math.MinInt16, []byte{0xd1, 0x80, 0x00}},
+		{math.MinInt16 - 1, []byte{0xd2, 0xff, 0xff, 0x7f, 0xff}},
+		{math.MinInt32, []byte{0xd2, 0x80, 0x00, 0x00, 0x00}},
+		{math.MinInt32 - 1, []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}},
+		{math.MinInt64, []byte{0xd3, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+	} {
+		if got := appendMsgpackInt(nil, tt.v); !bytes.Equal(got, tt.want) {
+			t.Errorf("appendMsgpackInt(%d) = % x, want % x", tt.v, got, tt.want)
+		}
+	}
+}
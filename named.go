@@ -0,0 +1,79 @@
+package log
+
+import "strings"
+
+// Named returns a logger derived from log whose entries carry the name,
+// joined to log's own name with a dot, as in "db" and then "db.pool".
+// Formats show the name as the logger member or field. Levels set with
+// SetNameLevel apply to the logger's name and the names below it.
+func (log *Logger) Named(name string) *Logger {
+	d := log.derive()
+	if d.name != "" {
+		name = d.name + "." + name
+	}
+	d.name = name
+	return d
+}
+
+// SetNameLevel sets the minimum level of the named loggers called name or
+// with names below it, such as "db" for "db.pool", in place of their own
+// minimum levels. The most specific name wins:
+//
+//	logger.SetNameLevel("db", log.LevelDebug)
+//	logger.SetNameLevel("db.pool", log.LevelWarn)
+//
+// It applies to all loggers sharing log's output, whenever they were made.
+func (log *Logger) SetNameLevel(name string, l Level) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	// Readers load the map without locking, so it is copied on write.
+	m := make(map[string]Level)
+	if old := r.nameLevels.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	m[name] = l
+	r.nameLevels.Store(&m)
+}
+
+// ClearNameLevel removes the level set for name with SetNameLevel.
+func (log *Logger) ClearNameLevel(name string) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	old := r.nameLevels.Load()
+	if old == nil {
+		return
+	}
+	m := make(map[string]Level, len(*old))
+	for k, v := range *old {
+		if k != name {
+			m[k] = v
+		}
+	}
+	r.nameLevels.Store(&m)
+}
+
+// minLevel returns the minimum level of log. Called with log locked.
+func (log *Logger) minLevel() Level {
+	min := log.settings().min
+	if log.name == "" {
+		return min
+	}
+	m := log.root.nameLevels.Load()
+	if m == nil {
+		return min
+	}
+	for name := log.name; ; {
+		if l, ok := (*m)[name]; ok {
+			return l
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			return min
+		}
+		name = name[:i]
+	}
+}
@@ -0,0 +1,164 @@
+package log
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Reconnection attempts of a NetWriter back off from netMinBackoff to
+// netMaxBackoff. A write taking longer than netWriteTimeout counts as a
+// failed connection.
+const (
+	netMinBackoff   = 100 * time.Millisecond
+	netMaxBackoff   = 30 * time.Second
+	netDialTimeout  = 10 * time.Second
+	netWriteTimeout = 10 * time.Second
+)
+
+// A NetWriter writes to a TCP or UDP address, reconnecting in the
+// background with exponential backoff whenever the connection fails.
+// While it is down, up to a given number of writes are queued and sent
+// once it is back, the oldest dropped first. A peer that stops reading
+// counts as down once a write takes too long. Writes only fail once the
+// writer is closed.
+type NetWriter struct {
+	network, addr string
+	max           int
+	timeout       time.Duration // of each write
+	quit          chan struct{}
+	wg            sync.WaitGroup
+
+	mu      sync.Mutex
+	conn    net.Conn
+	queue   [][]byte
+	dropped uint64
+	dialing bool
+	closed  bool
+}
+
+// NewNetWriter creates a writer to addr on network, such as "tcp" or
+// "udp", queueing up to queue writes while disconnected. It connects in
+// the background; writes until then are queued too.
+func NewNetWriter(network, addr string, queue int) *NetWriter {
+	w := &NetWriter{network: network, addr: addr, max: queue, timeout: netWriteTimeout, quit: make(chan struct{})}
+	w.redial()
+	return w
+}
+
+// Write implements io.Writer.
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	if w.conn != nil {
+		if err := w.send(w.conn, p); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		w.redial()
+	}
+	if w.max <= 0 {
+		w.dropped++
+		return len(p), nil
+	}
+	if len(w.queue) == w.max {
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+		w.dropped++
+	}
+	w.queue = append(w.queue, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+// redial starts reconnecting unless that is in progress. Called with w
+// locked.
+func (w *NetWriter) redial() {
+	if w.dialing {
+		return
+	}
+	w.dialing = true
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		for backoff := netMinBackoff; ; backoff = min(2*backoff, netMaxBackoff) {
+			if conn, err := net.DialTimeout(w.network, w.addr, netDialTimeout); err == nil && w.connected(conn) {
+				return
+			}
+			select {
+			case <-w.quit:
+				return
+			case <-time.After(backoff):
+			}
+		}
+	}()
+}
+
+// connected sends the queued writes over conn and makes it the connection
+// of w, reporting whether that succeeded.
+func (w *NetWriter) connected(conn net.Conn) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		conn.Close()
+		return true
+	}
+	for len(w.queue) > 0 {
+		if err := w.send(conn, w.queue[0]); err != nil {
+			conn.Close()
+			return false
+		}
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+	}
+	w.queue = nil
+	w.conn = conn
+	w.dialing = false
+	return true
+}
+
+// send writes p to conn within the write timeout. Called with w locked.
+func (w *NetWriter) send(conn net.Conn, p []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(p)
+	return err
+}
+
+// Dropped returns the number of writes dropped because the queue was full.
+func (w *NetWriter) Dropped() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// Close stops reconnecting and closes the connection. Writes still queued
+// are lost and reported in the error.
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+	w.closed = true
+	close(w.quit)
+	var err error
+	if n := len(w.queue); n > 0 {
+		err = fmt.Errorf("log: %d writes to %s not sent", n, w.addr)
+	}
+	w.queue = nil
+	if w.conn != nil {
+		if cerr := w.conn.Close(); err == nil {
+			err = cerr
+		}
+		w.conn = nil
+	}
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
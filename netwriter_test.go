@@ -0,0 +1,86 @@
+package log
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stalledListener accepts connections and never reads from them.
+func stalledListener(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no TCP: %v", err)
+	}
+	var mu sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, c)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		ln.Close()
+		mu.Lock()
+		for _, c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	})
+	return ln
+}
+
+func TestNetWriterStalledPeer(t *testing.T) {
+	ln := stalledListener(t)
+	w := NewNetWriter("tcp", ln.Addr().String(), 4)
+	defer w.Close()
+	w.mu.Lock()
+	w.timeout = 50 * time.Millisecond
+	w.mu.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		w.mu.Lock()
+		up := w.conn != nil
+		w.mu.Unlock()
+		if up {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("not connected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Fill the socket buffers until a write times out.
+	chunk := bytes.Repeat([]byte("x"), 1<<20)
+	for i := 0; ; i++ {
+		start := time.Now()
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Fatalf("write blocked for %v", d)
+		}
+		w.mu.Lock()
+		down, queued := w.conn == nil, len(w.queue)
+		w.mu.Unlock()
+		if down {
+			if queued == 0 {
+				t.Error("timed out write not queued")
+			}
+			break
+		}
+		if i == 1000 {
+			t.Fatal("no write timed out")
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !unix
+
+package log
+
+// oNoFollow is zero where opening cannot refuse symbolic links; overwrite
+// still checks that it opened the file it was given.
+const oNoFollow = 0
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"sort"
+	"strconv"
+)
+
+// OTelEncoder encodes entries as JSON log records following the
+// OpenTelemetry logs data model, one per line. Field names and value
+// encodings are those of OTLP/JSON, so collectors can ingest the records
+// with a file or stream receiver.
+type OTelEncoder struct {
+	resource map[string]string
+	keys     []string
+}
+
+// NewOTelEncoder creates an encoder including the resource attributes,
+// such as service.name, in every record.
+func NewOTelEncoder(resource map[string]string) *OTelEncoder {
+	enc := &OTelEncoder{resource: resource}
+	for k := range resource {
+		enc.keys = append(enc.keys, k)
+	}
+	sort.Strings(enc.keys)
+	return enc
+}
+
+// otelSeverity returns the OpenTelemetry severity number and text of l.
+func otelSeverity(l Level) (int, string) {
+	switch baseLevel(l) {
+	case LevelTrace:
+		return 1, "TRACE"
+	case LevelDebug:
+		return 5, "DEBUG"
+	case LevelInfo:
+		return 9, "INFO"
+	case LevelWarn:
+		return 13, "WARN"
+	default:
+		return 17, "ERROR"
+	}
+}
+
+func appendOTelAttr(buf []byte, key, value string) []byte {
+	buf = append(buf, `{"key":`...)
+	buf = AppendJSONString(buf, key)
+	buf = append(buf, `,"value":{"stringValue":`...)
+	buf = AppendJSONString(buf, value)
+	return append(buf, "}}"...)
+}
+
+// appendOTelField appends f as an attribute, keeping numbers and booleans
+// typed.
+func appendOTelField(buf []byte, f Field) []byte {
+	buf = append(buf, `{"key":`...)
+	buf = AppendJSONString(buf, f.Key)
+	buf = append(buf, `,"value":{`...)
+	switch v := f.Value.(type) {
+	case bool:
+		buf = append(buf, `"boolValue":`...)
+		buf = strconv.AppendBool(buf, v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
+		// OTLP/JSON encodes 64 bit integers as strings.
+		buf = append(buf, `"intValue":"`...)
+		buf = append(buf, fieldString(v)...)
+		buf = append(buf, '"')
+	case float32, float64:
+		buf = append(buf, `"doubleValue":`...)
+		buf = AppendJSONValue(buf, v)
+	default:
+		buf = append(buf, `"stringValue":`...)
+		buf = AppendJSONString(buf, fieldString(v))
+	}
+	return append(buf, "}}"...)
+}
+
+// Encode implements Encoder. The trace_id and span_id fields, see
+// SetSpanFunc, become the traceId and spanId of the record.
+func (enc *OTelEncoder) Encode(buf []byte, e *Entry) []byte {
+	num, text := otelSeverity(e.Level)
+	ts := strconv.FormatInt(e.Time.UnixNano(), 10)
+	buf = append(buf, `{"timeUnixNano":"`...)
+	buf = append(buf, ts...)
+	buf = append(buf, `","observedTimeUnixNano":"`...)
+	buf = append(buf, ts...)
+	buf = append(buf, `","severityNumber":`...)
+	buf = strconv.AppendInt(buf, int64(num), 10)
+	buf = append(buf, `,"severityText":"`...)
+	buf = append(buf, text...)
+	buf = append(buf, `","body":{"stringValue":`...)
+	buf = AppendJSONString(buf, e.Message)
+	buf = append(buf, '}')
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "trace_id":
+			buf = append(buf, `,"traceId":`...)
+			buf = AppendJSONString(buf, fieldString(f.Value))
+		case "span_id":
+			buf = append(buf, `,"spanId":`...)
+			buf = AppendJSONString(buf, fieldString(f.Value))
+		}
+	}
+	buf = append(buf, `,"attributes":[`...)
+	if e.File != "" {
+		buf = appendOTelAttr(buf, "code.filepath", e.File)
+		buf = append(buf, `,{"key":"code.lineno","value":{"intValue":"`...)
+		buf = strconv.AppendInt(buf, int64(e.Line), 10)
+		buf = append(buf, `"}}`...)
+		if e.Func != "" {
+			buf = append(buf, ',')
+			buf = appendOTelAttr(buf, "code.function", e.Func)
+		}
+	}
+	if e.Logger != "" {
+		if e.File != "" {
+			buf = append(buf, ',')
+		}
+		buf = appendOTelAttr(buf, "logger.name", e.Logger)
+	}
+	sep := e.File != "" || e.Logger != ""
+	for _, f := range e.Fields {
+		if f.Key == "trace_id" || f.Key == "span_id" {
+			continue
+		}
+		if sep {
+			buf = append(buf, ',')
+		}
+		buf = appendOTelField(buf, f)
+		sep = true
+	}
+	buf = append(buf, `],"resource":{"attributes":[`...)
+	for i, k := range enc.keys {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendOTelAttr(buf, k, enc.resource[k])
+	}
+	return append(buf, "]}}\n"...)
+}
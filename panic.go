@@ -0,0 +1,86 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// FormatPanic describes a value recovered from a panic as a message and
+// fields. The fields hold the dynamic type of the value, the value itself
+// for errors and composite values, and the stack of the calling goroutine,
+// which during a deferred recover still includes the panicking frames.
+func FormatPanic(v interface{}) (string, []Field) {
+	fields := []Field{{Key: "panic.type", Value: fmt.Sprintf("%T", v)}}
+	var msg string
+	switch v := v.(type) {
+	case error:
+		msg = v.Error()
+		fields = append(fields, Field{Key: "error", Value: v})
+	case string:
+		msg = v
+	case fmt.Stringer:
+		msg = v.String()
+	default:
+		msg = fmt.Sprintf("%v", v)
+		fields = append(fields, Field{Key: "panic.value", Value: fmt.Sprintf("%+v", v)})
+	}
+	fields = append(fields, Field{Key: "stack", Value: formatStack(callers())})
+	return "panic: " + msg, fields
+}
+
+// LogPanic logs a value recovered from a panic at the error level, as
+// described by FormatPanic.
+func (log *Logger) LogPanic(v interface{}) {
+	msg, fields := FormatPanic(v)
+	log.output(2, LevelError, "", msg, fields)
+}
+
+// LogRecover recovers from a panic, logs it at the error level as
+// described by FormatPanic, and if errp is not nil stores an error
+// describing the panic in it. It must be deferred directly:
+//
+//	func work() (err error) {
+//		defer log.LogRecover("work", &err)
+//		...
+//	}
+//
+// The entry is attributed to the function that panicked.
+func (log *Logger) LogRecover(name string, errp *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	msg, fields := FormatPanic(v)
+	prefix := "panic: "
+	if name != "" {
+		prefix = name + ": panic: "
+		msg = name + ": " + msg
+	}
+	log.output(panicDepth()+1, LevelError, "", msg, fields)
+	if errp != nil {
+		if err, ok := v.(error); ok {
+			*errp = fmt.Errorf("%s%w", prefix, err)
+		} else {
+			*errp = errors.New(msg)
+		}
+	}
+}
+
+// panicDepth returns the depth, relative to its caller, of the function
+// that panicked, skipping the runtime's panic machinery.
+func panicDepth() int {
+	pc := make([]uintptr, 32)
+	pc = pc[:runtime.Callers(3, pc)]
+	frames := runtime.CallersFrames(pc)
+	for d := 1; ; d++ {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			return d
+		}
+		if !more {
+			return 1
+		}
+	}
+}
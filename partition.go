@@ -0,0 +1,101 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// A Route directs the entries at or above Min to W, encoded with Enc or,
+// if Enc is nil, the encoder of the logger or partition it belongs to.
+type Route struct {
+	Min Level
+	W   io.Writer
+	Enc Encoder
+
+	tty bool
+}
+
+// A Partition is a sink splitting entries over several writers by level,
+// for example everything to app.log and warnings and errors to error.log
+// as well. Each writer may be a separately configured file.
+type Partition struct {
+	mu     sync.Mutex
+	enc    Encoder
+	routes []Route
+	buf    []byte
+	rbuf   []byte // for routes with their own encoder
+}
+
+// NewPartition creates a partition encoding entries with enc, or the
+// route's own encoder, for every route whose minimum level they reach. A
+// nil enc selects the text format.
+func NewPartition(enc Encoder, routes ...Route) *Partition {
+	if enc == nil {
+		enc = &TextEncoder{}
+	}
+	return &Partition{enc: enc, routes: routes}
+}
+
+// WriteEntry implements Sink.
+func (p *Partition) WriteEntry(e *Entry) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf = p.buf[0:0]
+	var err error
+	for _, r := range p.routes {
+		if e.Level < r.Min {
+			continue
+		}
+		var b []byte
+		if r.Enc != nil {
+			p.rbuf = r.Enc.Encode(p.rbuf[0:0], e)
+			b = p.rbuf
+		} else {
+			if len(p.buf) == 0 {
+				p.buf = p.enc.Encode(p.buf, e)
+			}
+			b = p.buf
+		}
+		if _, werr := r.W.Write(b); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// Flush flushes the writers of all routes.
+func (p *Partition) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, r := range p.routes {
+		errs = append(errs, flushOutput(r.W))
+	}
+	return errors.Join(errs...)
+}
+
+// Reopen reopens the writers of all routes that can be reopened, such as
+// a File.
+func (p *Partition) Reopen() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, r := range p.routes {
+		if ro, ok := r.W.(reopener); ok {
+			errs = append(errs, ro.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes the writers of all routes.
+func (p *Partition) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var errs []error
+	for _, r := range p.routes {
+		errs = append(errs, closeOutput(r.W))
+	}
+	return errors.Join(errs...)
+}
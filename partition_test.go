@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPartition(t *testing.T) {
+	var all, errs, js bytes.Buffer
+	tests := []struct {
+		name string
+		enc  Encoder
+	}{
+		{"nil encoder", nil},
+		{"text encoder", &TextEncoder{Flags: FlagNoDate}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all.Reset()
+			errs.Reset()
+			js.Reset()
+			p := NewPartition(tt.enc,
+				Route{W: &all},
+				Route{Min: LevelWarn, W: &errs},
+				Route{Min: LevelError, W: &js, Enc: &JSONEncoder{}},
+			)
+			l := New(nil, LevelInfo, 0, nil)
+			l.AddSink(p)
+			l.Info("info")
+			l.Warn("warn")
+			l.Error("error")
+			for _, c := range []struct {
+				name string
+				buf  *bytes.Buffer
+				want []string
+			}{
+				{"all", &all, []string{"info", "warn", "error"}},
+				{"errs", &errs, []string{"warn", "error"}},
+				{"json", &js, []string{`"msg":"error"`}},
+			} {
+				lines := strings.Split(strings.TrimSuffix(c.buf.String(), "\n"), "\n")
+				if len(lines) != len(c.want) {
+					t.Errorf("%s: %q, want %d lines", c.name, c.buf.String(), len(c.want))
+					continue
+				}
+				for i, w := range c.want {
+					if !strings.Contains(lines[i], w) {
+						t.Errorf("%s line %d = %q, want %q", c.name, i, lines[i], w)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// pkgLevels holds the levels set with SetPackageLevel.
+type pkgLevels struct {
+	levels map[string]Level
+	lowest Level
+	cache  sync.Map // program counter to *pkgLevel
+}
+
+type pkgLevel struct {
+	l  Level
+	ok bool
+}
+
+// SetPackageLevel sets the minimum level of entries logged from the
+// package with import path pkg, such as github.com/acme/svc/db, or from
+// packages below it, whatever logger they use. The most specific path
+// wins, and a package level takes precedence over the levels of
+// SetNameLevel. It applies to all loggers sharing log's output.
+//
+// Finding the package requires the caller of every call, so while package
+// levels are set, entries that may be enabled by them cost as much as with
+// the caller flags.
+func (log *Logger) SetPackageLevel(pkg string, l Level) {
+	log.updatePackageLevels(func(m map[string]Level) { m[pkg] = l })
+}
+
+// ClearPackageLevel removes the level set for pkg with SetPackageLevel.
+func (log *Logger) ClearPackageLevel(pkg string) {
+	log.updatePackageLevels(func(m map[string]Level) { delete(m, pkg) })
+}
+
+func (log *Logger) updatePackageLevels(f func(m map[string]Level)) {
+	r := log.root
+	r.Lock()
+	defer r.Unlock()
+	// Readers load the levels without locking, so they are copied on
+	// write.
+	m := make(map[string]Level)
+	if old := r.pkgLevels.Load(); old != nil {
+		for k, v := range old.levels {
+			m[k] = v
+		}
+	}
+	f(m)
+	if len(m) == 0 {
+		r.pkgLevels.Store(nil)
+		return
+	}
+	pl := &pkgLevels{levels: m}
+	first := true
+	for _, l := range m {
+		if first || l < pl.lowest {
+			pl.lowest = l
+			first = false
+		}
+	}
+	r.pkgLevels.Store(pl)
+}
+
+// level returns the level set for the package of the function at pc.
+func (pl *pkgLevels) level(pc uintptr) (Level, bool) {
+	if v, ok := pl.cache.Load(pc); ok {
+		p := v.(*pkgLevel)
+		return p.l, p.ok
+	}
+	fn, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	p := new(pkgLevel)
+	for pkg := funcPackage(fn.Function); pkg != ""; {
+		if l, ok := pl.levels[pkg]; ok {
+			p.l, p.ok = l, true
+			break
+		}
+		i := strings.LastIndexByte(pkg, '/')
+		if i < 0 {
+			break
+		}
+		pkg = pkg[:i]
+	}
+	pl.cache.Store(pc, p)
+	return p.l, p.ok
+}
+
+// funcPackage returns the import path of the package of the function
+// named fn, as in github.com/acme/svc/db for
+// github.com/acme/svc/db.(*Pool).Get.
+func funcPackage(fn string) string {
+	i := strings.LastIndexByte(fn, '/') + 1
+	if j := strings.IndexByte(fn[i:], '.'); j >= 0 {
+		return fn[:i+j]
+	}
+	return fn
+}
+
+// callerMinLevel returns the minimum level of log for the caller at pc,
+// which is found depth frames up if zero. Called with log locked.
+func (log *Logger) callerMinLevel(depth int, pc *uintptr) Level {
+	min := log.minLevel()
+	pl := log.root.pkgLevels.Load()
+	if pl == nil {
+		return min
+	}
+	if *pc == 0 {
+		pcs := [1]uintptr{}
+		if runtime.Callers(depth+1+log.skip, pcs[:]) == 0 {
+			return min
+		}
+		*pc = pcs[0]
+	}
+	if l, ok := pl.level(*pc); ok {
+		return l
+	}
+	return min
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PrettyEncoder encodes entries for reading during development rather
+// than for machines: the time of day, level, logger name and message line
+// up in columns, further lines of the message are indented under its
+// first and every field goes on a line of its own.
+//
+//	15:04:05.000 INFO  [http] request done
+//	    caller  = server.go:88
+//	    status  = 200
+//	    elapsed = 1.2ms
+type PrettyEncoder struct {
+	// Flags shorten the caller as in the text format.
+	Flags     Flags
+	PathDepth int // see SetPathDepth
+	// Color colors the level, time and keys with the theme, which is
+	// DefaultTheme if nil.
+	Color bool
+	Theme *Theme
+}
+
+// prettyIndent is the indentation of the field lines.
+const prettyIndent = "    "
+
+// Encode implements Encoder.
+func (enc *PrettyEncoder) Encode(buf []byte, e *Entry) []byte {
+	theme := enc.Theme
+	if theme == nil {
+		theme = &DefaultTheme
+	}
+	color := func(c string) string {
+		if enc.Color {
+			return c
+		}
+		return ""
+	}
+	buf = appendColored(buf, color(theme.Time), e.Time.Format("15:04:05.000"))
+	buf = append(buf, ' ')
+	level := strings.ToUpper(e.Level.String())
+	for len(level) < 5 {
+		level += " "
+	}
+	buf = appendColored(buf, color(theme.Levels[levelIndex(baseLevel(e.Level))]), level)
+	buf = append(buf, ' ')
+	width := len("15:04:05.000 ") + len(level) + 1
+	if e.Logger != "" {
+		start := len(buf)
+		buf = append(buf, '[')
+		buf = appendEscaped(buf, e.Logger)
+		buf = append(buf, "] "...)
+		width += utf8.RuneCount(buf[start:])
+	}
+	buf = appendIndented(buf, e.Message, width)
+	buf = append(buf, '\n')
+
+	var keys []string
+	var values []string
+	if e.File != "" && enc.Flags&callerFlags != 0 {
+		file := e.File
+		if enc.Flags&FlagShortPath != 0 {
+			file = shortPath(file, enc.PathDepth)
+		}
+		keys = append(keys, "caller")
+		values = append(values, file+":"+strconv.Itoa(e.Line))
+	}
+	for _, f := range e.Fields {
+		keys = append(keys, string(appendEscaped(nil, f.Key)))
+		values = append(values, fieldString(f.Value))
+	}
+	keyWidth := 0
+	for _, k := range keys {
+		keyWidth = max(keyWidth, len(k))
+	}
+	for i, k := range keys {
+		buf = append(buf, prettyIndent...)
+		buf = appendColored(buf, color(theme.Caller), k)
+		for j := len(k); j < keyWidth; j++ {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, " = "...)
+		buf = appendIndented(buf, values[i], len(prettyIndent)+keyWidth+3)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// appendIndented appends s, indenting the lines after the first by n
+// spaces. Control characters other than the newlines are escaped, so the
+// lines cannot pass for entries or move the cursor.
+func appendIndented(buf []byte, s string, n int) []byte {
+	s = strings.TrimRight(s, "\n")
+	for {
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return appendEscaped(buf, s)
+		}
+		buf = appendEscaped(buf, s[:i])
+		buf = append(buf, '\n')
+		for j := 0; j < n; j++ {
+			buf = append(buf, ' ')
+		}
+		s = s[i+1:]
+	}
+}
@@ -0,0 +1,24 @@
+package log
+
+import (
+	"os"
+	"sync"
+)
+
+// processInfo returns the host name and the process ID, looked up once.
+var processInfo = sync.OnceValues(func() (string, int) {
+	host, _ := os.Hostname()
+	return host, os.Getpid()
+})
+
+// processFields adds the fields of FlagHostname and FlagPID to e.
+func processFields(e *Entry, flags Flags) {
+	host, pid := processInfo()
+	e.Fields = e.Fields[:len(e.Fields):len(e.Fields)]
+	if flags&FlagHostname != 0 {
+		e.Fields = append(e.Fields, Field{Key: "hostname", Value: host})
+	}
+	if flags&FlagPID != 0 {
+		e.Fields = append(e.Fields, Field{Key: "pid", Value: pid})
+	}
+}
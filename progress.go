@@ -0,0 +1,84 @@
+package log
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A Progress logs the progress of a long running operation at most once
+// per interval, with the count, percentage, rate and estimated time
+// remaining when the total is known.
+//
+//	p := logger.NewProgress("import", total, 10*time.Second)
+//	for _, r := range records {
+//		...
+//		p.Add(1)
+//	}
+//	p.Done()
+type Progress struct {
+	log      *Logger
+	name     string
+	total    int64
+	interval time.Duration
+
+	mu    sync.Mutex
+	n     int64
+	start time.Time
+	last  time.Time
+}
+
+// NewProgress starts tracking the operation name, which has total steps;
+// a total of zero or less means unknown. Entries are logged at the info
+// level.
+func (log *Logger) NewProgress(name string, total int64, interval time.Duration) *Progress {
+	now := log.now()
+	return &Progress{log: log, name: name, total: total, interval: interval, start: now, last: now}
+}
+
+// Add records n more completed steps and logs the progress if the interval
+// has passed.
+func (p *Progress) Add(n int64) {
+	now := p.log.now()
+	p.mu.Lock()
+	p.n += n
+	if now.Sub(p.last) < p.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.last = now
+	fields := p.fields(now)
+	p.mu.Unlock()
+	p.log.output(2, LevelInfo, "", p.name+": in progress", fields)
+}
+
+// Done logs the final count and rate.
+func (p *Progress) Done() {
+	now := p.log.now()
+	p.mu.Lock()
+	fields := p.fields(now)
+	p.mu.Unlock()
+	p.log.output(2, LevelInfo, "", p.name+": done", fields)
+}
+
+// fields describes the progress at now. Called with p.mu held.
+func (p *Progress) fields(now time.Time) []Field {
+	elapsed := now.Sub(p.start)
+	fields := []Field{{Key: "count", Value: p.n}}
+	if p.total > 0 {
+		fields = append(fields, Field{Key: "total", Value: p.total},
+			Field{Key: "percent", Value: float64(int(float64(p.n)/float64(p.total)*1000)) / 10})
+	}
+	var rate float64
+	if secs := elapsed.Seconds(); secs > 0 {
+		rate = float64(p.n) / secs
+	}
+	// Formatted here, as large floats would come out in exponent form.
+	fields = append(fields, Field{Key: "rate", Value: strconv.FormatFloat(rate, 'f', 1, 64)},
+		Field{Key: "elapsed", Value: elapsed.Round(time.Millisecond)})
+	if p.total > 0 && rate > 0 && p.n < p.total {
+		eta := time.Duration(float64(p.total-p.n) / rate * float64(time.Second))
+		fields = append(fields, Field{Key: "eta", Value: eta.Round(time.Second)})
+	}
+	return fields
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressRate(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{3, "rate=0.3"},
+		{1234, "rate=123.4"},
+		{12345678, "rate=1234567.8"},
+		{123456789012, "rate=12345678901.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf, LevelInfo, 0, nil)
+			now := time.Unix(1000, 0)
+			l.SetClock(func() time.Time { return now })
+			p := l.NewProgress("import", 0, time.Hour)
+			p.Add(tt.n)
+			now = now.Add(10 * time.Second)
+			p.Done()
+			if got := buf.String(); !strings.Contains(got, " "+tt.want+" ") {
+				t.Errorf("got %q, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+)
+
+const maxLine = 1 << 20
+
+// A Receiver accepts entries sent over the network by other processes, one
+// per line in a format DecodeEntry understands, and writes them through a
+// local logger, which encodes them as it is configured to and fans them
+// out to its sinks. It makes a small aggregation relay.
+type Receiver struct {
+	log *Logger
+
+	mu    sync.Mutex
+	lns   map[net.Listener]struct{}
+	conns map[net.Conn]struct{}
+	wg    sync.WaitGroup
+	done  bool
+}
+
+// NewReceiver creates a receiver writing to log.
+func NewReceiver(log *Logger) *Receiver {
+	return &Receiver{
+		log:   log,
+		lns:   make(map[net.Listener]struct{}),
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// ErrReceiverClosed is returned by Serve after Close.
+var ErrReceiverClosed = errors.New("log: receiver closed")
+
+// Serve accepts connections on ln, reading entries from each until it is
+// closed. It returns when ln fails or the receiver is closed.
+func (rv *Receiver) Serve(ln net.Listener) error {
+	rv.mu.Lock()
+	if rv.done {
+		rv.mu.Unlock()
+		return ErrReceiverClosed
+	}
+	rv.lns[ln] = struct{}{}
+	rv.mu.Unlock()
+	defer func() {
+		rv.mu.Lock()
+		delete(rv.lns, ln)
+		rv.mu.Unlock()
+	}()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			rv.mu.Lock()
+			done := rv.done
+			rv.mu.Unlock()
+			if done {
+				return ErrReceiverClosed
+			}
+			return err
+		}
+		rv.mu.Lock()
+		if rv.done {
+			rv.mu.Unlock()
+			c.Close()
+			return ErrReceiverClosed
+		}
+		rv.conns[c] = struct{}{}
+		rv.wg.Add(1)
+		rv.mu.Unlock()
+		go func() {
+			defer rv.wg.Done()
+			rv.read(c)
+			c.Close()
+			rv.mu.Lock()
+			delete(rv.conns, c)
+			rv.mu.Unlock()
+		}()
+	}
+}
+
+// ServeHTTP reads entries from the body of POST requests.
+func (rv *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := rv.read(req.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// read writes the entries in r to the logger.
+func (rv *Receiver) read(r io.Reader) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), maxLine)
+	for s.Scan() {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+		e := newEntry()
+		err := DecodeEntry(s.Bytes(), e)
+		if err == nil {
+			err = rv.log.WriteEntry(e)
+		}
+		e.Release()
+		if err == ErrClosed {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// Close stops all Serve calls and waits for open connections to finish.
+func (rv *Receiver) Close() error {
+	rv.mu.Lock()
+	rv.done = true
+	var errs []error
+	for ln := range rv.lns {
+		errs = append(errs, ln.Close())
+	}
+	for c := range rv.conns {
+		c.Close()
+	}
+	rv.mu.Unlock()
+	rv.wg.Wait()
+	return errors.Join(errs...)
+}
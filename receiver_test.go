@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReceiverPipeline(t *testing.T) {
+	var remote bytes.Buffer
+	sender := New(&remote, LevelInfo, 0, nil)
+	sender.SetEncoder(&JSONEncoder{})
+	sender.WithFields(F("user", "bob"), F("password", "hunter2")).Info("login")
+	sender.Info("noise")
+
+	var out bytes.Buffer
+	l := New(&out, LevelInfo, 0, nil)
+	l.SetRedaction(RedactRule{Key: regexp.MustCompile(`^password$`)})
+	l.AddFilter(func(e *Entry) bool { return e.Message != "noise" })
+	var hooked, after []string
+	l.AddHook(func(e *Entry) {
+		hooked = append(hooked, e.Message)
+		e.AddField("received", true)
+	})
+	l.AddAfterHook(func(e *Entry, err error) { after = append(after, e.Message) })
+	if err := NewReceiver(l).read(&remote); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"redacted", !strings.Contains(got, "hunter2")},
+		{"other fields kept", strings.Contains(got, "user=bob")},
+		{"filtered", !strings.Contains(got, "noise")},
+		{"hook fields", strings.Contains(got, "received=true")},
+		{"hooks", len(hooked) == 1 && hooked[0] == "login"},
+		{"after hooks", len(after) == 1 && after[0] == "login"},
+	}
+	for _, tt := range tests {
+		if !tt.ok {
+			t.Errorf("%s: output %q, hooks %q, after hooks %q", tt.name, got, hooked, after)
+		}
+	}
+}
+
+func TestWriteEntryLeavesEntry(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out, LevelInfo, 0, nil)
+	l.SetRedaction(RedactRule{Key: regexp.MustCompile(`^token$`)})
+	l.AddHook(func(e *Entry) { e.Message = "changed" })
+	e := &Entry{Level: LevelInfo, Message: "sent", Fields: []Field{{Key: "token", Value: "abc"}}}
+	if err := l.WriteEntry(e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Message != "sent" || e.Fields[0].Value != "abc" {
+		t.Errorf("entry changed to %+v", e)
+	}
+	if strings.Contains(out.String(), "abc") {
+		t.Errorf("not redacted: %q", out.String())
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+)
+
+// Redacted replaces the values of redacted fields.
+const Redacted = "[REDACTED]"
+
+// A RedactRule hides the values of the fields whose keys match Key. With a
+// HashKey the value is replaced by a keyed hash of it, so entries about
+// the same value can still be correlated, otherwise by Redacted.
+type RedactRule struct {
+	Key     *regexp.Regexp
+	HashKey []byte
+}
+
+// DefaultRedactKeys matches the keys of common secrets and personal data.
+var DefaultRedactKeys = regexp.MustCompile(`(?i)passw(?:or)?d|secret|token|api[_.-]?key|authorization|cookie|ssn`)
+
+// SetRedaction makes log and the loggers derived from it hide field values
+// by the first of rules whose key matches, before they are expanded into
+// templates, encoded or passed to hooks and sinks. Only the top level
+// value of a field is hidden; keys within maps or structs are not
+// inspected. No rules disables redaction.
+func (log *Logger) SetRedaction(rules ...RedactRule) {
+	r := log.root
+	r.Lock()
+	r.redact = rules
+	r.Unlock()
+}
+
+// redactFields returns fields with the values hidden by rules, copying
+// them if any is.
+func redactFields(rules []RedactRule, fields []Field) []Field {
+	copied := false
+	for i, f := range fields {
+		for _, rule := range rules {
+			if !rule.Key.MatchString(f.Key) {
+				continue
+			}
+			if !copied {
+				fields = append([]Field(nil), fields...)
+				copied = true
+			}
+			fields[i].Value = rule.hide(f.Value)
+			break
+		}
+	}
+	return fields
+}
+
+func (rule *RedactRule) hide(v interface{}) string {
+	if rule.HashKey == nil {
+		return Redacted
+	}
+	h := hmac.New(sha256.New, rule.HashKey)
+	h.Write([]byte(fieldString(v)))
+	return "hmac:" + hex.EncodeToString(h.Sum(nil)[:12])
+}
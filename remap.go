@@ -0,0 +1,49 @@
+package log
+
+// A RemapSink passes entries to another sink with their levels changed,
+// for example to downgrade the warnings of a noisy dependency for an
+// alerting sink while files keep them as they are:
+//
+//	logger.AddSink(log.Remap(alerts, func(e *log.Entry) log.Level {
+//		if e.Level == log.LevelWarn && strings.HasPrefix(e.Func, "github.com/vendor/") {
+//			return log.LevelInfo
+//		}
+//		return e.Level
+//	}))
+//
+// Rules looking at the caller need a logger flag that captures it.
+type RemapSink struct {
+	s     Sink
+	level func(e *Entry) Level
+}
+
+// Remap returns a sink passing entries to s with the levels level returns.
+func Remap(s Sink, level func(e *Entry) Level) *RemapSink {
+	return &RemapSink{s: s, level: level}
+}
+
+// WriteEntry implements Sink.
+func (rs *RemapSink) WriteEntry(e *Entry) error {
+	l := rs.level(e)
+	if l == e.Level {
+		return rs.s.WriteEntry(e)
+	}
+	// e is shared with the other sinks, so pass a copy.
+	c := newEntry()
+	defer c.Release()
+	refs := c.refs
+	*c = *e
+	c.refs = refs
+	c.Level = l
+	return rs.s.WriteEntry(c)
+}
+
+// Flush flushes the underlying sink.
+func (rs *RemapSink) Flush() error {
+	return flushOutput(rs.s)
+}
+
+// Close flushes and closes the underlying sink.
+func (rs *RemapSink) Close() error {
+	return closeOutput(rs.s)
+}
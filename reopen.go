@@ -0,0 +1,143 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// A File is a log file that can be reopened by name, so that after an
+// external tool such as logrotate moved it away, logging continues in a
+// new file under the old name rather than in the moved one.
+type File struct {
+	mu   sync.Mutex
+	name string
+	perm os.FileMode
+	f    *os.File
+}
+
+// OpenFile opens the file name for appending, creating it with mode perm
+// if needed.
+func OpenFile(name string, perm os.FileMode) (*File, error) {
+	f := &File{name: name, perm: perm}
+	var err error
+	if f.f, err = f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) open() (*os.File, error) {
+	return os.OpenFile(f.name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, f.perm)
+}
+
+// Write appends p to the file.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return 0, os.ErrClosed
+	}
+	return f.f.Write(p)
+}
+
+// Reopen closes the file and opens the file name again. If that fails,
+// writes continue to go to the old file. After Close it returns
+// os.ErrClosed.
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	nf, err := f.open()
+	if err != nil {
+		return err
+	}
+	old := f.f
+	f.f = nf
+	return old.Close()
+}
+
+// Name returns the name of the file.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Sync commits the file to stable storage.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	return f.f.Sync()
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
+
+type reopener interface {
+	Reopen() error
+}
+
+// Reopen reopens the outputs and sinks of log's root logger that can be
+// reopened, such as a File, also where it is wrapped in a BufferedWriter,
+// AsyncWriter, TimeoutWriter or Partition.
+func (log *Logger) Reopen() error {
+	r := log.root
+	r.Lock()
+	outs := r.outputs()
+	r.Unlock()
+	var errs []error
+	for _, out := range outs {
+		if ro, ok := out.(reopener); ok {
+			errs = append(errs, ro.Reopen())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reopenOnSignal calls Reopen whenever the process receives one of sigs.
+func (log *Logger) reopenOnSignal(sigs []os.Signal) (stop func()) {
+	return onSignal(sigs, func(os.Signal) {
+		if err := log.Reopen(); err != nil {
+			log.Errorf("reopening outputs: %v", err)
+		}
+	})
+}
+
+// onSignal calls f with each of sigs the process receives until the
+// returned function is called.
+func onSignal(sigs []os.Signal, f func(os.Signal)) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-c:
+				f(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
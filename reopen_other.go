@@ -0,0 +1,16 @@
+//go:build !unix
+
+package log
+
+import "os"
+
+// ReopenOnSignal calls Reopen whenever the process receives one of sigs.
+// Errors are logged. Without SIGHUP there is no default signal, so with
+// none given it does nothing. The returned stop function stops the
+// handling.
+func (log *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		return func() {}
+	}
+	return log.reopenOnSignal(sigs)
+}
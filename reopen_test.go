@@ -0,0 +1,81 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReopen(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(f *File) (io.Writer, Sink)
+	}{
+		{"File", func(f *File) (io.Writer, Sink) { return f, nil }},
+		{"BufferedWriter", func(f *File) (io.Writer, Sink) { return NewBufferedWriter(f, 0, time.Hour), nil }},
+		{"AsyncWriter", func(f *File) (io.Writer, Sink) { return NewAsyncWriter(f, 0), nil }},
+		{"TimeoutWriter", func(f *File) (io.Writer, Sink) { return NewTimeoutWriter(f, time.Second), nil }},
+		{"Partition", func(f *File) (io.Writer, Sink) {
+			return nil, NewPartition(&TextEncoder{Flags: FlagNoDate}, Route{W: f})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "app.log")
+			f, err := OpenFile(name, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, sink := tt.wrap(f)
+			l := New(out, LevelInfo, FlagNoDate, nil)
+			if sink != nil {
+				l.AddSink(sink)
+			}
+			l.Info("before")
+			if err := l.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Rename(name, name+".1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.Reopen(); err != nil {
+				t.Fatal(err)
+			}
+			l.Info("after")
+			if err := l.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			for file, want := range map[string]string{name + ".1": "before", name: "after"} {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s := string(data); strings.Count(s, "\n") != 1 || !strings.Contains(s, want) {
+					t.Errorf("%s = %q, want the %s entry alone", filepath.Base(file), s, want)
+				}
+			}
+			f.Close()
+		})
+	}
+}
+
+func TestFileReopenAfterClose(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenFile(name, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Reopen after Close = %v, want os.ErrClosed", err)
+	}
+	if _, err := f.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write after Reopen = %v, want os.ErrClosed", err)
+	}
+}
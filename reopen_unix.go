@@ -0,0 +1,18 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// ReopenOnSignal calls Reopen whenever the process receives one of sigs,
+// SIGHUP if none are given, as logrotate sends after moving the files.
+// Errors are logged. The returned stop function stops the handling.
+func (log *Logger) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	return log.reopenOnSignal(sigs)
+}
@@ -0,0 +1,177 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// A report is an entry forwarded to an error tracking service.
+type report struct {
+	Level       Level
+	Time        time.Time
+	Message     string
+	File        string
+	Line        int
+	Fingerprint string
+	Stack       []runtime.Frame
+	Fields      map[string]interface{}
+}
+
+// A reporter forwards entries at or above a minimum level to an error
+// tracking service in the background, sending each fingerprint at most once
+// per window. It is shared by the Rollbar, Bugsnag and Sentry sinks.
+type reporter struct {
+	min    Level
+	window time.Duration
+	client *http.Client
+	build  func(r *report) (*http.Request, error)
+	queue  chan *http.Request
+	done   chan struct{}
+
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	err    error
+	closed bool
+}
+
+func newReporter(min Level, window time.Duration, client *http.Client, build func(*report) (*http.Request, error)) *reporter {
+	if window <= 0 {
+		window = time.Minute
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rep := &reporter{
+		min:    min,
+		window: window,
+		client: client,
+		build:  build,
+		queue:  make(chan *http.Request, 64),
+		done:   make(chan struct{}),
+		seen:   make(map[string]time.Time),
+	}
+	go rep.run()
+	return rep
+}
+
+func (rep *reporter) WriteEntry(e *Entry) error {
+	if e.Level < rep.min {
+		return nil
+	}
+	fp := fingerprint(e)
+	rep.mu.Lock()
+	err := rep.err
+	rep.err = nil
+	if rep.closed {
+		rep.mu.Unlock()
+		return err
+	}
+	if t, ok := rep.seen[fp]; ok && e.Time.Sub(t) < rep.window {
+		rep.mu.Unlock()
+		return err
+	}
+	rep.seen[fp] = e.Time
+	for k, t := range rep.seen {
+		if e.Time.Sub(t) >= rep.window {
+			delete(rep.seen, k)
+		}
+	}
+	rep.mu.Unlock()
+	// The stack, if any, is the one FlagStack or LogPanic took when the
+	// entry was logged; by now the goroutine is elsewhere, if it is
+	// still the same goroutine at all.
+	var stack []runtime.Frame
+	var fields map[string]interface{}
+	if len(e.Fields) > 0 {
+		fields = make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			if s, ok := f.Value.(string); ok && f.Key == "stack" && stack == nil {
+				stack = parseStack(s)
+				continue
+			}
+			fields[f.Key] = jsonSafe(f.Value)
+		}
+	}
+	req, berr := rep.build(&report{
+		Level:       e.Level,
+		Time:        e.Time,
+		Message:     e.Message,
+		File:        e.File,
+		Line:        e.Line,
+		Fingerprint: fp,
+		Stack:       stack,
+		Fields:      fields,
+	})
+	if berr != nil {
+		return berr
+	}
+	rep.mu.Lock()
+	if !rep.closed {
+		select {
+		case rep.queue <- req:
+		default:
+			// The service is not keeping up; errors are reported
+			// on a best effort basis.
+		}
+	}
+	rep.mu.Unlock()
+	return err
+}
+
+// jsonSafe returns v in a form encoding/json renders the way
+// AppendJSONValue does.
+func jsonSafe(v interface{}) interface{} {
+	return json.RawMessage(AppendJSONValue(nil, v))
+}
+
+func (rep *reporter) run() {
+	defer close(rep.done)
+	for req := range rep.queue {
+		err := rep.send(req)
+		if err != nil {
+			rep.mu.Lock()
+			rep.err = err
+			rep.mu.Unlock()
+		}
+	}
+}
+
+func (rep *reporter) send(req *http.Request) error {
+	resp, err := rep.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return &httpError{req.URL.Host, resp.Status}
+	}
+	return nil
+}
+
+// Close sends the queued reports and stops the reporter.
+func (rep *reporter) Close() error {
+	rep.mu.Lock()
+	if !rep.closed {
+		rep.closed = true
+		close(rep.queue)
+	}
+	rep.mu.Unlock()
+	<-rep.done
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	return rep.err
+}
+
+type httpError struct {
+	host   string
+	status string
+}
+
+func (e *httpError) Error() string {
+	return "log: " + e.host + ": " + e.status
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReporterStack(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		flags Flags
+		log   func(l *Logger)
+		stack bool
+	}{
+		{"no stack", 0, func(l *Logger) { l.Error("boom") }, false},
+		{"FlagStack", FlagStack, func(l *Logger) { l.Error("boom") }, true},
+		{"LogPanic", 0, func(l *Logger) { l.LogPanic(errors.New("boom")) }, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reports := make(chan *report, 1)
+			rep := newReporter(LevelError, 0, &http.Client{Transport: okTransport{}}, func(r *report) (*http.Request, error) {
+				reports <- r
+				return http.NewRequest("POST", "http://localhost", nil)
+			})
+			defer rep.Close()
+			l := New(io.Discard, LevelInfo, tt.flags, nil)
+			l.AddSink(goSink{rep})
+			tt.log(l)
+			r := <-reports
+			if _, ok := r.Fields["stack"]; ok {
+				t.Errorf("stack sent as a field too")
+			}
+			if !tt.stack {
+				if r.Stack != nil {
+					t.Errorf("Stack = %v, want none", r.Stack)
+				}
+				return
+			}
+			if len(r.Stack) == 0 {
+				t.Fatal("no stack")
+			}
+			// The frames of this package are left out, which leaves
+			// the test runner of the logging goroutine.
+			if f := r.Stack[0]; f.Function != "testing.tRunner" || !strings.HasSuffix(f.File, "testing.go") || f.Line == 0 {
+				t.Errorf("innermost frame %s at %s:%d, want testing.tRunner", f.Function, f.File, f.Line)
+			}
+		})
+	}
+}
+
+// goSink passes entries to a sink from another goroutine, like sinks
+// sending in the background do.
+type goSink struct{ s Sink }
+
+func (g goSink) WriteEntry(e *Entry) error {
+	e.Retain()
+	go func() {
+		g.s.WriteEntry(e)
+		e.Release()
+	}()
+	return nil
+}
+
+// okTransport answers every request with an empty 200 response.
+type okTransport struct{}
+
+func (okTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: http.NoBody, Request: req}, nil
+}
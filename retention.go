@@ -0,0 +1,107 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// A Retention is a policy for removing old log files.
+type Retention struct {
+	// MaxAge is how long files are kept after their last modification.
+	// Zero keeps files forever.
+	MaxAge time.Duration
+	// SecureDelete overwrites files with zeros before removing them.
+	SecureDelete bool
+	// OnDelete is called before a file is removed, for example to archive
+	// it or record the deletion. If it returns an error the file is kept.
+	OnDelete func(path string) error
+}
+
+// Enforce applies the policy to the files matching the glob pattern.
+// Symbolic links are removed without touching their targets; other files
+// that are not regular are left alone. It keeps going after a failure and
+// returns all errors joined.
+func (r *Retention) Enforce(pattern string) error {
+	if r.MaxAge <= 0 {
+		return nil
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-r.MaxAge)
+	var errs []error
+	for _, path := range paths {
+		fi, err := os.Lstat(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !removable(fi) || fi.ModTime().After(cutoff) {
+			continue
+		}
+		if err := r.remove(path, fi); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// removable reports whether fi, as returned by os.Lstat, is a regular
+// file or a symbolic link.
+func removable(fi os.FileInfo) bool {
+	return fi.Mode().IsRegular() || fi.Mode()&os.ModeSymlink != 0
+}
+
+// remove removes the file at path described by fi, as returned by
+// os.Lstat. Symbolic links are unlinked and never overwritten.
+func (r *Retention) remove(path string, fi os.FileInfo) error {
+	if r.OnDelete != nil {
+		if err := r.OnDelete(path); err != nil {
+			return err
+		}
+	}
+	if r.SecureDelete && fi.Mode().IsRegular() {
+		if err := overwrite(path, fi); err != nil {
+			return err
+		}
+	}
+	return os.Remove(path)
+}
+
+// overwrite fills the regular file at path described by fi with zeros. It
+// refuses to follow symbolic links and files replaced since fi was taken.
+func overwrite(path string, fi os.FileInfo) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|oNoFollow, 0)
+	if err != nil {
+		return err
+	}
+	if cur, err := f.Stat(); err != nil || !os.SameFile(fi, cur) {
+		f.Close()
+		if err == nil {
+			err = fmt.Errorf("log: %s changed before deletion", path)
+		}
+		return err
+	}
+	size := fi.Size()
+	zero := make([]byte, 32*1024)
+	for size > 0 {
+		n := int64(len(zero))
+		if size < n {
+			n = size
+		}
+		if _, err := f.Write(zero[:n]); err != nil {
+			f.Close()
+			return err
+		}
+		size -= n
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
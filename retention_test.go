@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// symlinkSetup creates an old log file and a symbolic link matching the
+// pattern dir/app-*.log, pointing to a file outside dir. It returns the
+// paths of the file, the link and the target.
+func symlinkSetup(t *testing.T) (file, link, target string) {
+	t.Helper()
+	dir := t.TempDir()
+	target = filepath.Join(t.TempDir(), "precious")
+	if err := os.WriteFile(target, []byte("keep me"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file = filepath.Join(dir, "app-2000.log")
+	if err := os.WriteFile(file, []byte("old entries"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(file, old, old); err != nil {
+		t.Fatal(err)
+	}
+	link = filepath.Join(dir, "app-2001.log")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	return file, link, target
+}
+
+func TestRetentionSymlink(t *testing.T) {
+	tests := []struct {
+		name     string
+		enforce  func(file, link string) error
+		fileGone bool
+	}{
+		{"Enforce", func(file, link string) error {
+			time.Sleep(10 * time.Millisecond) // let the link age past MaxAge
+			r := &Retention{MaxAge: time.Millisecond, SecureDelete: true}
+			return r.Enforce(filepath.Join(filepath.Dir(file), "app-*.log"))
+		}, true},
+		{"prune", func(file, link string) error {
+			// Make the link the oldest backup, the one to go.
+			future := time.Now().Add(time.Hour)
+			if err := os.Chtimes(file, future, future); err != nil {
+				return err
+			}
+			f := &RotatingFile{c: RotateConfig{
+				Pattern:    filepath.Join(filepath.Dir(file), "app-{2006}.log"),
+				MaxBackups: 1,
+				Retention:  Retention{SecureDelete: true},
+			}}
+			return f.prune(filepath.Join(filepath.Dir(file), "app-2002.log"))
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, link, target := symlinkSetup(t)
+			if err := tt.enforce(file, link); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Lstat(file); os.IsNotExist(err) != tt.fileGone {
+				t.Errorf("file removed = %v, want %v", os.IsNotExist(err), tt.fileGone)
+			}
+			data, err := os.ReadFile(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte("keep me")) {
+				t.Errorf("target of link changed to %q", data)
+			}
+			if _, err := os.Lstat(link); !os.IsNotExist(err) {
+				t.Errorf("link not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestOverwriteRefusesSymlink(t *testing.T) {
+	_, link, target := symlinkSetup(t)
+	fi, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := overwrite(link, fi); err == nil {
+		t.Error("overwrite of a symbolic link succeeded")
+	}
+	if data, _ := os.ReadFile(target); !bytes.Equal(data, []byte("keep me")) {
+		t.Errorf("target of link changed to %q", data)
+	}
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// RollbarConfig configures a Rollbar sink.
+type RollbarConfig struct {
+	Token       string
+	Environment string
+	// Endpoint defaults to https://api.rollbar.com/api/1/item/.
+	Endpoint string
+	// DedupWindow is how long an entry logged from the same place is not
+	// reported again, one minute by default.
+	DedupWindow time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Rollbar is a sink reporting error entries, with the stack FlagStack
+// or LogPanic added to them, to Rollbar.
+type Rollbar struct {
+	*reporter
+	c RollbarConfig
+}
+
+// NewRollbar creates a Rollbar sink. Close flushes it.
+func NewRollbar(c RollbarConfig) *Rollbar {
+	if c.Endpoint == "" {
+		c.Endpoint = "https://api.rollbar.com/api/1/item/"
+	}
+	r := &Rollbar{c: c}
+	r.reporter = newReporter(LevelError, c.DedupWindow, c.Client, r.build)
+	return r
+}
+
+type rollbarFrame struct {
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	Method   string `json:"method"`
+}
+
+func (r *Rollbar) build(rep *report) (*http.Request, error) {
+	// Rollbar lists the most recent call last.
+	frames := make([]rollbarFrame, len(rep.Stack))
+	for i, f := range rep.Stack {
+		frames[len(frames)-1-i] = rollbarFrame{f.File, f.Line, f.Function}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{
+			"environment": r.c.Environment,
+			"level":       "error",
+			"timestamp":   rep.Time.Unix(),
+			"platform":    "go",
+			"language":    "go",
+			"fingerprint": rep.Fingerprint,
+			"custom":      rep.Fields,
+			"body": map[string]interface{}{
+				"trace": map[string]interface{}{
+					"frames": frames,
+					"exception": map[string]string{
+						"class":   "error",
+						"message": rep.Message,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", r.c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Rollbar-Access-Token", r.c.Token)
+	return req, nil
+}
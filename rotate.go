@@ -0,0 +1,312 @@
+package log
+
+import (
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotateConfig configures a RotatingFile.
+type RotateConfig struct {
+	// Pattern is the file name with time layouts in braces, as in
+	// "logs/app-{2006-01-02}.log", formatted with the start of the
+	// current period.
+	Pattern string
+	// Every is the rotation period, such as time.Hour. Periods of whole
+	// days start at local midnight, others at multiples of Every. Zero
+	// means a day.
+	Every time.Duration
+	// Perm is the mode of new files, 0644 by default.
+	Perm os.FileMode
+	// SignKey, if set, signs each file after it is rotated out, see
+	// SignFile.
+	SignKey ed25519.PrivateKey
+	// Compress gzips files after they are rotated out, adding ".gz" to
+	// their names.
+	Compress bool
+	// MaxBackups is the number of rotated files kept; zero keeps all.
+	MaxBackups int
+	// Retention removes rotated files older than its MaxAge. Its
+	// OnDelete and SecureDelete also apply to files removed because of
+	// MaxBackups.
+	Retention Retention
+	// OnError, if set, is called with the errors of the cleanup after
+	// rotation, which runs in the background. Otherwise they are
+	// returned by the next Write or by Close, so a logger writing to the
+	// file passes them to its error handler, see SetErrorHandler.
+	OnError func(err error)
+}
+
+// A RotatingFile is a writer appending to a file whose name changes with
+// time, for example one file per day. The file for the current period is
+// opened on the first write in the period; files are never renamed.
+type RotatingFile struct {
+	mu   sync.Mutex
+	c    RotateConfig
+	f    *os.File
+	name string
+	next time.Time // start of the next period
+	err  error     // of background cleanups, for the next Write
+
+	cleanMu sync.Mutex // serializes cleanups
+	cleanWG sync.WaitGroup
+}
+
+// NewRotatingFile creates a rotating file and opens the file of the
+// current period.
+func NewRotatingFile(c RotateConfig) (*RotatingFile, error) {
+	if c.Every <= 0 {
+		c.Every = 24 * time.Hour
+	}
+	if c.Perm == 0 {
+		c.Perm = 0644
+	}
+	f := &RotatingFile{c: c}
+	if err := f.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// periodStart returns the start of the period containing t.
+func periodStart(t time.Time, every time.Duration) time.Time {
+	if every%(24*time.Hour) == 0 {
+		y, m, d := t.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		days := int(every / (24 * time.Hour))
+		return day.AddDate(0, 0, -(day.YearDay()-1)%days)
+	}
+	return t.Truncate(every)
+}
+
+// expandPattern formats the time layouts in braces in pattern with t, or
+// replaces them with glob if it is not empty.
+func expandPattern(pattern string, t time.Time, glob string) string {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(pattern, '{')
+		j := strings.IndexByte(pattern, '}')
+		if i < 0 || j < i {
+			break
+		}
+		b.WriteString(pattern[:i])
+		if glob != "" {
+			b.WriteString(glob)
+		} else {
+			b.WriteString(t.Format(pattern[i+1 : j]))
+		}
+		pattern = pattern[j+1:]
+	}
+	b.WriteString(pattern)
+	return b.String()
+}
+
+// rotate switches to the file of the period containing now. Called with
+// f.mu held.
+func (f *RotatingFile) rotate(now time.Time) error {
+	start := periodStart(now, f.c.Every)
+	name := expandPattern(f.c.Pattern, start, "")
+	f.next = periodStart(start.Add(f.c.Every+f.c.Every/2), f.c.Every)
+	if f.f != nil && name == f.name {
+		return nil
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	nf, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, f.c.Perm)
+	if err != nil {
+		return err
+	}
+	old, oldName := f.f, f.name
+	f.f, f.name = nf, name
+	if old == nil {
+		return nil
+	}
+	err = old.Close()
+	if err == nil {
+		f.cleanWG.Add(1)
+		go f.cleanup(oldName, name)
+	}
+	return err
+}
+
+// cleanup compresses and signs the file rotated out and removes the
+// backups beyond the limits.
+func (f *RotatingFile) cleanup(old, current string) {
+	defer f.cleanWG.Done()
+	f.cleanMu.Lock()
+	defer f.cleanMu.Unlock()
+	if err := f.archive(old); err != nil {
+		f.report(err)
+	}
+	if err := f.prune(current); err != nil {
+		f.report(err)
+	}
+}
+
+// report passes err of a cleanup to OnError or keeps it for the next
+// write.
+func (f *RotatingFile) report(err error) {
+	if f.c.OnError != nil {
+		f.c.OnError(err)
+		return
+	}
+	f.mu.Lock()
+	f.err = errors.Join(f.err, fmt.Errorf("log: rotate: %w", err))
+	f.mu.Unlock()
+}
+
+func (f *RotatingFile) archive(path string) error {
+	if f.c.Compress {
+		if err := gzipFile(path); err != nil {
+			return err
+		}
+		path += ".gz"
+	}
+	if f.c.SignKey != nil {
+		return SignFile(path, f.c.SignKey)
+	}
+	return nil
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	err = errors.Join(err, zw.Close(), out.Close())
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	return os.Remove(path)
+}
+
+// prune removes the rotated files beyond MaxBackups or older than
+// Retention.MaxAge, along with their signatures.
+func (f *RotatingFile) prune(current string) error {
+	if f.c.MaxBackups <= 0 && f.c.Retention.MaxAge <= 0 {
+		return nil
+	}
+	pattern := expandPattern(f.c.Pattern, time.Time{}, "*")
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	// Without an extension in the pattern, the first glob matches the
+	// compressed files and signatures too.
+	gz, _ := filepath.Glob(pattern + ".gz")
+	type backup struct {
+		path string
+		fi   os.FileInfo
+	}
+	var backups []backup
+	seen := make(map[string]bool)
+	for _, path := range append(paths, gz...) {
+		if path == current || seen[path] || strings.HasSuffix(path, SignatureSuffix) {
+			continue
+		}
+		seen[path] = true
+		fi, err := os.Lstat(path)
+		if err != nil || !removable(fi) {
+			continue
+		}
+		backups = append(backups, backup{path, fi})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].fi.ModTime().After(backups[j].fi.ModTime())
+	})
+	cutoff := time.Now().Add(-f.c.Retention.MaxAge)
+	var errs []error
+	for i, b := range backups {
+		if (f.c.MaxBackups <= 0 || i < f.c.MaxBackups) &&
+			(f.c.Retention.MaxAge <= 0 || b.fi.ModTime().After(cutoff)) {
+			continue
+		}
+		if err := f.c.Retention.remove(b.path, b.fi); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := os.Remove(b.path + SignatureSuffix); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Write appends p to the file of the current period, rotating first if a
+// new period has begun. If the new file cannot be opened, the entry goes
+// to the old one and the error is returned, as are the errors of
+// cleanups since the last write.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return 0, os.ErrClosed
+	}
+	var rerr error
+	if now := time.Now(); !now.Before(f.next) {
+		rerr = f.rotate(now)
+	}
+	n, err := f.f.Write(p)
+	err = errors.Join(err, rerr, f.err)
+	f.err = nil
+	return n, err
+}
+
+// Name returns the name of the current file.
+func (f *RotatingFile) Name() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.name
+}
+
+// Sync commits the current file to stable storage.
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return os.ErrClosed
+	}
+	return f.f.Sync()
+}
+
+// Close closes the current file and waits for pending cleanups,
+// returning their errors unless OnError took them.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.f == nil {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+	err := f.f.Close()
+	f.f = nil
+	f.mu.Unlock()
+	f.cleanWG.Wait()
+	f.mu.Lock()
+	err = errors.Join(err, f.err)
+	f.err = nil
+	f.mu.Unlock()
+	return err
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateCleanupErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		onError   bool // set RotateConfig.OnError
+		toHandler bool // the error reaches the logger's error handler
+	}{
+		{"error handler", false, true},
+		{"OnError", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fromOnError error
+			c := RotateConfig{
+				Pattern:  filepath.Join(t.TempDir(), "app-{2006-01-02}.log"),
+				Compress: true,
+			}
+			if tt.onError {
+				c.OnError = func(err error) { fromOnError = err }
+			}
+			f, err := NewRotatingFile(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var handled []error
+			l := New(f, LevelInfo, 0, nil)
+			l.SetErrorHandler(func(err error) { handled = append(handled, err) })
+
+			// Compressing a file that is gone fails.
+			f.cleanWG.Add(1)
+			f.cleanup(filepath.Join(t.TempDir(), "gone.log"), f.Name())
+			l.Info("after the cleanup")
+			l.Info("again")
+
+			if got := len(handled) == 1; got != tt.toHandler {
+				t.Errorf("errors handled by the logger: %v", handled)
+			}
+			if got := fromOnError != nil; got != tt.onError {
+				t.Errorf("error passed to OnError: %v", fromOnError)
+			}
+			for _, err := range append(handled, fromOnError) {
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("unexpected error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRotateCleanup(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		compress bool
+		sign     bool
+		want     []string // besides the current file
+	}{
+		{"plain", false, false, []string{"app-2000-01-03.log", "app-2000-01-04.log"}},
+		{"compress", true, false, []string{"app-2000-01-03.log", "app-2000-01-04.log.gz"}},
+		{"compress and sign", true, true, []string{
+			"app-2000-01-03.log", "app-2000-01-04.log.gz", "app-2000-01-04.log.gz.sig",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := RotateConfig{
+				Pattern:    filepath.Join(dir, "app-{2006-01-02}.log"),
+				Compress:   tt.compress,
+				MaxBackups: 2,
+			}
+			if tt.sign {
+				c.SignKey = key
+			}
+			f, err := NewRotatingFile(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			// Three older backups and the file just rotated out.
+			for i, name := range []string{"app-2000-01-01.log", "app-2000-01-02.log", "app-2000-01-03.log", "app-2000-01-04.log"} {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte("entry\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				mtime := time.Now().Add(time.Duration(i-4) * time.Hour)
+				if err := os.Chtimes(path, mtime, mtime); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f.cleanWG.Add(1)
+			f.cleanup(filepath.Join(dir, "app-2000-01-04.log"), f.Name())
+			if _, err := f.Write(nil); err != nil {
+				t.Fatalf("cleanup failed: %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				if filepath.Join(dir, e.Name()) != f.Name() {
+					got = append(got, e.Name())
+				}
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("files %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("files %q, want %q", got, tt.want)
+				}
+			}
+			if tt.sign {
+				if err := VerifyFile(filepath.Join(dir, "app-2000-01-04.log.gz"), pub); err != nil {
+					t.Errorf("VerifyFile: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRotatePruneExtensionless(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		c    RotateConfig
+		want []string // besides the current file
+	}{
+		{"compress and sign", RotateConfig{Compress: true, SignKey: key, MaxBackups: 2}, []string{
+			"app-2000-01-03.gz", "app-2000-01-03.gz.sig", "app-2000-01-04.gz", "app-2000-01-04.gz.sig",
+		}},
+		{"sign", RotateConfig{SignKey: key, MaxBackups: 2}, []string{
+			"app-2000-01-03", "app-2000-01-03.sig", "app-2000-01-04", "app-2000-01-04.sig",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c := tt.c
+			c.Pattern = filepath.Join(dir, "app-{2006-01-02}")
+			f, err := NewRotatingFile(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			// Rotate out four files, oldest first, as the cleanups would.
+			for i, name := range []string{"app-2000-01-01", "app-2000-01-02", "app-2000-01-03", "app-2000-01-04"} {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte("entry\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				f.cleanWG.Add(1)
+				f.cleanup(path, f.Name())
+				// Later cleanups leave their files newer.
+				for _, p := range []string{path, path + ".gz", path + SignatureSuffix, path + ".gz" + SignatureSuffix} {
+					mtime := time.Now().Add(time.Duration(i-4) * time.Hour)
+					os.Chtimes(p, mtime, mtime)
+				}
+			}
+			if _, err := f.Write(nil); err != nil {
+				t.Fatalf("cleanup failed: %v", err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				if filepath.Join(dir, e.Name()) != f.Name() {
+					got = append(got, e.Name())
+				}
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("files %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// SetSampleRate keeps only the given fraction of the entries at level l,
+// chosen at random, so that for example debug entries can be sampled at 1%
+// and info entries at 10% while warnings are always kept. A rate of one or
+// more keeps everything.
+func (log *Logger) SetSampleRate(l Level, rate float64) {
+	log.Lock()
+	defer log.Unlock()
+	log.updateSettings(localSample, func(s *levelSettings) {
+		// Derived loggers share the map, so it is copied on write.
+		m := make(map[Level]float64, len(s.sample)+1)
+		for k, v := range s.sample {
+			m[k] = v
+		}
+		if rate >= 1 {
+			delete(m, l)
+		} else {
+			m[l] = rate
+		}
+		if len(m) == 0 {
+			m = nil
+		}
+		s.sample = m
+	})
+}
+
+// noExempt is the exemption level of loggers that sample every level.
+const noExempt = Level(1<<31 - 1)
+
+// SetSamplingExempt makes entries at level l and above bypass sampling and
+// rate limits, so thinning out noise never hides failures. Loggers derived
+// afterwards, such as those of Tenants, inherit the setting.
+func (log *Logger) SetSamplingExempt(l Level) {
+	log.Lock()
+	log.exempt = l
+	log.Unlock()
+}
+
+// sampled decides whether to keep an entry at level l with the sample
+// rates.
+func sampled(sample map[Level]float64, l Level) bool {
+	rate, ok := sample[l]
+	return !ok || rand.Float64() < rate
+}
+
+// thinned reports whether sampling or rate limits drop an entry with the
+// message s made from tmpl. Called with log locked.
+func (log *Logger) thinned(now time.Time, l Level, tmpl, s string, fields []Field) bool {
+	set := log.settings()
+	return !sampled(set.sample, l) ||
+		(log.lim != nil && !log.lim.allow(now)) ||
+		(log.keyLim != nil && !log.keyLim.allow(now, fields)) ||
+		(set.msgs != nil && !set.msgs.allow(now, l, msgKey(tmpl, s)))
+}
+
+// tallies is the number of message counters of a msgSampler. Messages
+// sharing a counter are sampled together.
+const tallies = 4096
+
+// A msgSampler lets through the first entries with a message in each
+// window, then every thereafter-th.
+type msgSampler struct {
+	mu         sync.Mutex
+	window     time.Duration
+	first      int
+	thereafter int
+	counts     [tallies]struct {
+		start time.Time
+		n     int
+	}
+}
+
+// SetSampling thins out repeated messages: of the entries with the same
+// level and message, or format string, within each window only the first
+// are kept, then every thereafter-th. A thereafter of zero drops the rest;
+// a window of zero turns sampling off. Derived loggers share the counts.
+func (log *Logger) SetSampling(window time.Duration, first, thereafter int) {
+	log.Lock()
+	defer log.Unlock()
+	log.updateSettings(localMsgs, func(s *levelSettings) {
+		if window <= 0 {
+			s.msgs = nil
+		} else {
+			s.msgs = &msgSampler{window: window, first: first, thereafter: thereafter}
+		}
+	})
+}
+
+// allow counts an entry and decides whether to keep it.
+func (ms *msgSampler) allow(now time.Time, l Level, msg string) bool {
+	h := fnv.New32a()
+	h.Write([]byte{byte(l)})
+	h.Write([]byte(msg))
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	c := &ms.counts[h.Sum32()%tallies]
+	if now.Sub(c.start) >= ms.window {
+		c.start = now
+		c.n = 0
+	}
+	c.n++
+	if c.n <= ms.first {
+		return true
+	}
+	return ms.thereafter > 0 && (c.n-ms.first)%ms.thereafter == 0
+}
+
+// msgKey returns what identifies the message s made from tmpl.
+func msgKey(tmpl, s string) string {
+	if tmpl != "" {
+		return tmpl
+	}
+	return s
+}
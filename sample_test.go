@@ -0,0 +1,115 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampleRate(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelDebug, FlagNoDate|FlagNoTime, nil)
+	l.SetSampleRate(LevelDebug, 0)
+	l.SetSampleRate(LevelInfo, 0.5)
+	for i := 0; i < 1000; i++ {
+		l.Debug("debug")
+		l.Info("info")
+		l.Warn("warn")
+	}
+	out := buf.String()
+	if n := strings.Count(out, " debug\n"); n != 0 {
+		t.Errorf("%d debug entries at rate 0, want none", n)
+	}
+	if n := strings.Count(out, " info\n"); n < 400 || n > 600 {
+		t.Errorf("%d of 1000 info entries at rate 0.5", n)
+	}
+	if n := strings.Count(out, " warn\n"); n != 1000 {
+		t.Errorf("%d of 1000 unsampled warn entries", n)
+	}
+
+	buf.Reset()
+	l.SetSampleRate(LevelDebug, 1)
+	l.Named("child").Debug("debug")
+	if !strings.Contains(buf.String(), " debug") {
+		t.Errorf("debug entry dropped after rate 1:\n%s", buf.String())
+	}
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	l.SetSampling(time.Hour, 2, 3)
+	for i := 1; i <= 10; i++ {
+		l.Infof("a %d", i)
+		l.Info("b")
+		l.Named("child").Warn("b")
+	}
+	// The first two, then every third: 1, 2, 5 and 8.
+	got := buf.String()
+	for _, want := range []string{"a 1\n", "a 2\n", "a 5\n", "a 8\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q dropped:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, " a "); n != 4 {
+		t.Errorf("%d entries of the format kept, want 4", n)
+	}
+	if n := strings.Count(got, "INFO  b\n"); n != 4 {
+		t.Errorf("%d info b entries kept, want 4", n)
+	}
+	if n := strings.Count(got, "WARN  b"); n != 4 {
+		t.Errorf("%d warn b entries kept, want 4 counted apart from info", n)
+	}
+
+	buf.Reset()
+	l.SetSampling(0, 0, 0)
+	for i := 0; i < 10; i++ {
+		l.Info("b")
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 10 {
+		t.Errorf("%d of 10 entries kept with sampling off", n)
+	}
+}
+
+func TestSamplingWindow(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	now := time.Now()
+	l.SetClock(func() time.Time { return now })
+	l.SetSampling(time.Minute, 1, 0)
+	l.Info("a")
+	l.Info("a")
+	now = now.Add(time.Minute)
+	l.Info("a")
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("%d entries kept, want the first of each window:\n%s", n, buf.String())
+	}
+}
+
+func TestSamplingExempt(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+	l.SetSampleRate(LevelInfo, 0)
+	l.SetSampleRate(LevelWarn, 0)
+	l.SetSampleRate(LevelError, 0)
+	l.SetSampling(time.Hour, 1, 0)
+	l.SetSamplingExempt(LevelWarn)
+	tl := NewTenants(l, 1e-9, 1).Logger("acme")
+	child := tl.Named("child")
+	for i := 0; i < 3; i++ {
+		tl.Info("info")
+		tl.Warn("warn")
+		child.Error("error")
+	}
+	got := buf.String()
+	if strings.Contains(got, "info") {
+		t.Errorf("info entry kept below the exemption:\n%s", got)
+	}
+	if n := strings.Count(got, " warn\n"); n != 3 {
+		t.Errorf("%d of 3 exempt warn entries kept", n)
+	}
+	if n := strings.Count(got, " error"); n != 3 {
+		t.Errorf("%d of 3 exempt error entries of a derived logger kept", n)
+	}
+}
@@ -0,0 +1,139 @@
+package log
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SentryConfig configures a Sentry sink.
+type SentryConfig struct {
+	// DSN is the client key of the project, as in
+	// https://key@o1.ingest.sentry.io/42.
+	DSN         string
+	Environment string
+	Release     string
+	// Tags are the keys of the fields sent as tags, which Sentry indexes
+	// for searching. The other fields are sent as extra data.
+	Tags []string
+	// DedupWindow is how long an entry logged from the same place is not
+	// reported again, one minute by default.
+	DedupWindow time.Duration
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Sentry is a sink reporting error entries, with the stack FlagStack or
+// LogPanic added to them, to Sentry. Entries above the error level are
+// reported as fatal.
+type Sentry struct {
+	*reporter
+	c        SentryConfig
+	endpoint string
+	auth     string
+	tags     map[string]bool
+}
+
+// NewSentry creates a Sentry sink. Close flushes it.
+func NewSentry(c SentryConfig) (*Sentry, error) {
+	u, err := url.Parse(c.DSN)
+	if err != nil {
+		return nil, err
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || project == "" || project == "/" || project == "." {
+		return nil, errors.New("log: invalid Sentry DSN")
+	}
+	s := &Sentry{
+		c:        c,
+		endpoint: u.Scheme + "://" + u.Host + strings.TrimSuffix(u.Path, project) + "api/" + project + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=go-log/1, sentry_key=" + u.User.Username(),
+		tags:     make(map[string]bool, len(c.Tags)),
+	}
+	for _, k := range c.Tags {
+		s.tags[k] = true
+	}
+	s.reporter = newReporter(LevelError, c.DedupWindow, c.Client, s.build)
+	return s, nil
+}
+
+type sentryFrame struct {
+	Filename string `json:"filename"`
+	Function string `json:"function"`
+	Lineno   int    `json:"lineno"`
+}
+
+func (s *Sentry) build(rep *report) (*http.Request, error) {
+	// Sentry lists the most recent call last.
+	frames := make([]sentryFrame, len(rep.Stack))
+	for i, f := range rep.Stack {
+		frames[len(frames)-1-i] = sentryFrame{f.File, f.Function, f.Line}
+	}
+	tags := make(map[string]string)
+	extra := make(map[string]interface{})
+	for k, v := range rep.Fields {
+		if !s.tags[k] {
+			extra[k] = v
+			continue
+		}
+		raw := v.(json.RawMessage)
+		var str string
+		if json.Unmarshal(raw, &str) != nil {
+			str = string(raw)
+		}
+		tags[k] = str
+	}
+	level := "error"
+	if rep.Level > LevelError {
+		level = "fatal"
+	}
+	var id [16]byte
+	rand.Read(id[:])
+	eventID := hex.EncodeToString(id[:])
+	event, err := json.Marshal(map[string]interface{}{
+		"event_id":    eventID,
+		"timestamp":   rep.Time.UTC().Format(time.RFC3339Nano),
+		"level":       level,
+		"platform":    "go",
+		"environment": s.c.Environment,
+		"release":     s.c.Release,
+		"fingerprint": []string{rep.Fingerprint},
+		"message":     map[string]string{"formatted": rep.Message},
+		"tags":        tags,
+		"extra":       extra,
+		"exception": map[string]interface{}{
+			"values": []interface{}{
+				map[string]interface{}{
+					"type":       "error",
+					"value":      rep.Message,
+					"stacktrace": map[string]interface{}{"frames": frames},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	body.WriteString(`{"event_id":"` + eventID + `"}` + "\n")
+	body.WriteString(`{"type":"event","length":`)
+	body.WriteString(strconv.Itoa(len(event)))
+	body.WriteString("}\n")
+	body.Write(event)
+	body.WriteByte('\n')
+	req, err := http.NewRequest("POST", s.endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+	return req, nil
+}
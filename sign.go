@@ -0,0 +1,63 @@
+package log
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"os"
+)
+
+// SignatureSuffix is appended to a file name to form its signature file.
+const SignatureSuffix = ".sig"
+
+// ErrSignature is returned when a file does not match its signature.
+var ErrSignature = errors.New("log: signature mismatch")
+
+var signOpts = &ed25519.Options{Hash: crypto.SHA512, Context: "go-log"}
+
+func digestFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha512.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// SignFile writes a detached Ed25519 signature of the file at path to
+// path+SignatureSuffix. It is meant for files that are no longer written
+// to, such as rotated logs.
+func SignFile(path string, key ed25519.PrivateKey) error {
+	digest, err := digestFile(path)
+	if err != nil {
+		return err
+	}
+	sig, err := key.Sign(nil, digest, signOpts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+SignatureSuffix, sig, 0644)
+}
+
+// VerifyFile checks the file at path against its detached signature.
+// It returns ErrSignature if the file was modified.
+func VerifyFile(path string, pub ed25519.PublicKey) error {
+	sig, err := os.ReadFile(path + SignatureSuffix)
+	if err != nil {
+		return err
+	}
+	digest, err := digestFile(path)
+	if err != nil {
+		return err
+	}
+	if ed25519.VerifyWithOptions(pub, digest, sig, signOpts) != nil {
+		return ErrSignature
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignFile(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		change func(path string) error
+		pub    ed25519.PublicKey
+		want   error
+	}{
+		{"unchanged", func(string) error { return nil }, pub, nil},
+		{"appended", func(path string) error {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = f.WriteString("forged entry\n")
+			return err
+		}, pub, ErrSignature},
+		{"other key", func(string) error { return nil }, otherPub, ErrSignature},
+		{"no signature", func(path string) error { return os.Remove(path + SignatureSuffix) }, pub, fs.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, []byte("entry\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := SignFile(path, key); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.change(path); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyFile(path, tt.pub); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyFile = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package log
+
+import "io"
+
+// A Sink receives entries directly rather than as encoded output, for
+// destinations that take structured events.
+// WriteEntry is called with the logger locked and must not retain e
+// without calling its Retain method.
+type Sink interface {
+	WriteEntry(e *Entry) error
+}
+
+// AddSink adds a sink receiving every entry written by log and the loggers
+// derived from it.
+func (log *Logger) AddSink(s Sink) {
+	r := log.root
+	r.Lock()
+	r.sinks = append(r.sinks, s)
+	r.Unlock()
+}
+
+// AddOutput adds a writer receiving the entries of at least level min in
+// the logger's format, besides the writer passed to New. Each output has
+// its own minimum level, for example everything to a file and warnings and
+// errors to standard error as well:
+//
+//	logger := log.New(file, log.LevelDebug, 0, nil)
+//	logger.AddOutput(os.Stderr, log.LevelWarn)
+//
+// Entries below the logger's own minimum level reach no output.
+func (log *Logger) AddOutput(w io.Writer, min Level) {
+	log.AddRoute(Route{Min: min, W: w})
+}
+
+// AddRoute is AddOutput for a route, which may have an encoder of its own,
+// so each output can have its format, for example text on the console
+// and JSON in a file:
+//
+//	logger := log.New(os.Stderr, log.LevelInfo, 0, nil)
+//	logger.AddRoute(log.Route{W: file, Enc: &log.JSONEncoder{}})
+func (log *Logger) AddRoute(rt Route) {
+	r := log.root
+	r.Lock()
+	rt.tty = isTerminal(rt.W)
+	r.routes = append(r.routes, rt)
+	r.Unlock()
+}
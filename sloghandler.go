@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// levelFromSlog maps l to the level at or below it.
+func levelFromSlog(l slog.Level) Level {
+	switch {
+	case l < slog.LevelDebug:
+		return LevelTrace
+	case l < slog.LevelInfo:
+		return LevelDebug
+	case l < slog.LevelWarn:
+		return LevelInfo
+	case l < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// A Handler is a slog.Handler writing records through a Logger, so
+// libraries taking a *slog.Logger log with its level strings, format,
+// outputs and sinks. Attributes become fields, with the keys of groups
+// joined by dots, and the context of records is used as in
+// LogContext.
+type Handler struct {
+	log   *Logger
+	group string // prefix of the keys, ending in a dot
+}
+
+// NewHandler creates a handler writing to log.
+func NewHandler(log *Logger) *Handler {
+	return &Handler{log: log}
+}
+
+// Slog returns a slog.Logger writing to log.
+func (log *Logger) Slog() *slog.Logger {
+	return slog.New(NewHandler(log))
+}
+
+// Enabled implements slog.Handler. It reports false only for levels the
+// logging methods of the logger discard too; package levels, which need
+// the caller, are left to Handle.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return !h.log.discards(levelFromSlog(level))
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	fields := h.log.contextFields(ctx)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.group, a)
+		return true
+	})
+	now := r.Time
+	if now.IsZero() || h.log.root.clock.Load() != nil {
+		now = h.log.now()
+	}
+	return h.log.outputAt(0, r.PC, now, levelFromSlog(r.Level), "", r.Message, fields)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []Field
+	for _, a := range attrs {
+		fields = appendAttr(fields, h.group, a)
+	}
+	return &Handler{log: h.log.WithFields(fields...), group: h.group}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{log: h.log, group: h.group + name + "."}
+}
+
+// appendAttr appends a as fields with keys prefixed by prefix, following
+// the rules of slog.Handler for empty attributes and groups.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, g)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(l *Logger) *Logger
+		want  bool // debug entries enabled
+	}{
+		{"min level", func(l *Logger) *Logger { return l }, false},
+		{"name level", func(l *Logger) *Logger {
+			l.SetNameLevel("db", LevelDebug)
+			return l.Named("db")
+		}, true},
+		{"package level", func(l *Logger) *Logger {
+			l.SetPackageLevel("github.com/lucy/go-log", LevelDebug)
+			return l
+		}, true},
+		{"flight recorder", func(l *Logger) *Logger {
+			l.SetFlightRecorder(10, LevelDebug)
+			return l
+		}, true},
+		{"debug enabled", func(l *Logger) *Logger {
+			l.EnableDebugFor(time.Hour)
+			return l
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := tt.setup(New(&buf, LevelInfo, 0, nil))
+			if got := NewHandler(l).Enabled(context.Background(), slog.LevelDebug); got != tt.want {
+				t.Errorf("Enabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerPackageLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, 0, nil)
+	l.SetPackageLevel("github.com/lucy/go-log", LevelDebug)
+	l.Slog().Debug("from slog")
+	if !strings.Contains(buf.String(), "from slog") {
+		t.Errorf("debug entry of the package dropped: %q", buf.String())
+	}
+}
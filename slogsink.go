@@ -0,0 +1,60 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLevel maps l to the corresponding slog level.
+func slogLevel(l Level) slog.Level {
+	switch baseLevel(l) {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// SlogSink is a sink passing entries on to a slog.Handler.
+type SlogSink struct {
+	h slog.Handler
+}
+
+// NewSlogSink creates a sink writing to h.
+func NewSlogSink(h slog.Handler) *SlogSink {
+	return &SlogSink{h}
+}
+
+// WriteEntry implements Sink. The record's source is only known if the
+// logger captures callers.
+func (s *SlogSink) WriteEntry(e *Entry) error {
+	ctx := context.Background()
+	level := slogLevel(e.Level)
+	if !s.h.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(e.Time, level, e.Message, e.PC)
+	if e.Logger != "" {
+		r.AddAttrs(slog.String("logger", e.Logger))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	return s.h.Handle(ctx, r)
+}
+
+// NewSlog creates a logger whose entries are handled by h, so code using
+// this package can share a slog backend with code using slog directly.
+// Flags other than those capturing the caller have no effect on the
+// output.
+func NewSlog(h slog.Handler, minLevel Level, flags Flags) *Logger {
+	log := New(nil, minLevel, flags, nil)
+	log.AddSink(NewSlogSink(h))
+	return log
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// pkgPrefix is the prefix of the function names of this package.
+var pkgPrefix = reflect.TypeOf(Logger{}).PkgPath() + "."
+
+// callers returns the stack of the current goroutine without the frames of
+// this package, innermost first.
+func callers() []runtime.Frame {
+	pc := make([]uintptr, 64)
+	pc = pc[:runtime.Callers(2, pc)]
+	frames := runtime.CallersFrames(pc)
+	var stack []runtime.Frame
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPrefix) {
+			stack = append(stack, f)
+		}
+		if !more {
+			return stack
+		}
+	}
+}
+
+// formatStack formats frames like the stacks of goroutine dumps.
+func formatStack(stack []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// parseStack parses a stack written by formatStack.
+func parseStack(s string) []runtime.Frame {
+	var stack []runtime.Frame
+	for s != "" {
+		var fn, loc string
+		fn, s, _ = strings.Cut(s, "\n\t")
+		loc, s, _ = strings.Cut(s, "\n")
+		f := runtime.Frame{Function: fn, File: loc}
+		if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+			if n, err := strconv.Atoi(loc[i+1:]); err == nil {
+				f.File, f.Line = loc[:i], n
+			}
+		}
+		stack = append(stack, f)
+	}
+	return stack
+}
+
+// SetStackLevel sets the level from which FlagStack adds stacks.
+func (log *Logger) SetStackLevel(l Level) {
+	r := log.root
+	r.Lock()
+	r.stackMin = l
+	r.Unlock()
+}
+
+// hasField reports whether fields include key, such as the stack of a
+// panic.
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
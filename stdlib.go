@@ -0,0 +1,19 @@
+package log
+
+import stdlog "log"
+
+// CaptureStdlib redirects the output of the standard library's log
+// package to log at level l, so messages of dependencies using it get the
+// format, outputs and sinks of log. Its own prefix and flags are cleared.
+// The returned function restores the previous output, prefix and flags.
+func CaptureStdlib(log *Logger, l Level) (restore func()) {
+	w, prefix, flags := stdlog.Writer(), stdlog.Prefix(), stdlog.Flags()
+	stdlog.SetOutput(log.Writer(l))
+	stdlog.SetPrefix("")
+	stdlog.SetFlags(0)
+	return func() {
+		stdlog.SetOutput(w)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetFlags(flags)
+	}
+}
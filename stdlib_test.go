@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	stdlog "log"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// line returns the line of its caller plus off.
+func line(off int) string {
+	_, _, n, _ := runtime.Caller(1)
+	return "stdlib_test.go:" + strconv.Itoa(n+off)
+}
+
+func TestStdlibCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime|FlagShortPath, nil)
+	restore := CaptureStdlib(l, LevelInfo)
+	defer restore()
+	std := stdlog.New(l.Writer(LevelInfo), "", 0)
+	tests := []struct {
+		name string
+		log  func() string
+	}{
+		{"Print", func() string { stdlog.Print("x"); return line(0) }},
+		{"Printf", func() string { stdlog.Printf("%s", "x"); return line(0) }},
+		{"Output", func() string { stdlog.Output(1, "x"); return line(0) }},
+		{"Logger.Println", func() string { std.Println("x"); return line(0) }},
+		{"Fprintln", func() string { fmt.Fprintln(l.Writer(LevelInfo), "x"); return line(0) }},
+		{"Write", func() string { l.Writer(LevelInfo).Write([]byte("x\n")); return line(0) }},
+		{"Close", func() string {
+			w := l.Writer(LevelInfo)
+			w.Write([]byte("x"))
+			w.Close()
+			return line(-1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			want := tt.log()
+			if got := buf.String(); !strings.Contains(got, " "+want+": x") {
+				t.Errorf("got %q, want caller %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// badKey is the key of values that were given without one.
+const badKey = "!BADKEY"
+
+// KV turns alternating keys and values into fields, as in
+//
+//	log.InfoT("done", log.KV("user", id, "took", d)...)
+//
+// Fields among the arguments are taken as they are. A value that is not
+// preceded by a string key, after an odd number of arguments or in place
+// of a key, is kept under the key !BADKEY; FlagStrict makes logging it
+// panic.
+func KV(kv ...interface{}) []Field {
+	fields := make([]Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i++ {
+		switch k := kv[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			if i+1 < len(kv) {
+				fields = append(fields, Field{Key: k, Value: kv[i+1]})
+				i++
+			} else {
+				fields = append(fields, Field{Key: badKey, Value: k})
+			}
+		default:
+			fields = append(fields, Field{Key: badKey, Value: k})
+		}
+	}
+	return fields
+}
+
+// validKey reports whether key can be written unquoted in the text format
+// and read back: it must be non-empty valid UTF-8 without spaces, control
+// characters, '=', '"' or '\\'.
+func validKey(key string) bool {
+	if key == "" || !utf8.ValidString(key) {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c == 0x7f || c == '=' || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFields returns an error describing the first malformed field.
+func checkFields(fields []Field) error {
+	for _, f := range fields {
+		switch {
+		case f.Key == badKey:
+			return fmt.Errorf("log: value %#v has no key: odd number of key-value arguments or a key that is not a string", f.Value)
+		case !validKey(f.Key):
+			return fmt.Errorf("log: invalid field key %q", f.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SyslogConfig configures a Syslog sink.
+type SyslogConfig struct {
+	// Network is "tcp", "udp", "unix" or "unixgram". If it and Addr are
+	// empty the local syslog socket is used.
+	Network string
+	Addr    string
+	// Facility is the syslog facility, 1 (user) by default.
+	Facility int
+	// AppName defaults to the program name.
+	AppName string
+	// Hostname defaults to the name of the host.
+	Hostname string
+	// RFC3164 selects the older BSD format instead of RFC 5424.
+	RFC3164 bool
+}
+
+// A Syslog is a sink sending entries to a syslog daemon. Debug, info, warn
+// and error map to the severities debug, informational, warning and
+// error. Fields follow the message as in the text format. Over TCP,
+// messages are framed by octet counting as in RFC 6587.
+type Syslog struct {
+	mu     sync.Mutex
+	c      SyslogConfig
+	conn   net.Conn
+	pid    string
+	buf    []byte
+	closed bool
+}
+
+// NewSyslog creates a syslog sink and connects to the daemon. Lost
+// connections are reestablished on the next entry.
+func NewSyslog(c SyslogConfig) (*Syslog, error) {
+	if c.Facility == 0 {
+		c.Facility = 1
+	}
+	if c.AppName == "" {
+		c.AppName = filepath.Base(os.Args[0])
+	}
+	if c.Hostname == "" {
+		c.Hostname, _ = os.Hostname()
+	}
+	s := &Syslog{c: c, pid: strconv.Itoa(os.Getpid())}
+	if err := s.dial(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Syslog) dial() error {
+	if s.c.Network != "" || s.c.Addr != "" {
+		conn, err := net.Dial(s.c.Network, s.c.Addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		return nil
+	}
+	var err error
+	for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = net.Dial(network, path); err == nil {
+				s.conn = conn
+				s.c.Network = network
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// syslogSeverity returns the syslog severity of l.
+func syslogSeverity(l Level) int {
+	switch {
+	case l < LevelInfo:
+		// Syslog has nothing below debug.
+		return 7
+	case l < LevelWarn:
+		return 6
+	case l < LevelError:
+		return 4
+	default:
+		return 3
+	}
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (s *Syslog) format(buf []byte, e *Entry) []byte {
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(s.c.Facility*8+syslogSeverity(e.Level)), 10)
+	buf = append(buf, '>')
+	if s.c.RFC3164 {
+		buf = e.Time.AppendFormat(buf, time.Stamp)
+		buf = append(buf, ' ')
+		buf = append(buf, s.c.Hostname...)
+		buf = append(buf, ' ')
+		buf = append(buf, s.c.AppName...)
+		buf = append(buf, '[')
+		buf = append(buf, s.pid...)
+		buf = append(buf, "]: "...)
+	} else {
+		buf = append(buf, "1 "...)
+		buf = e.Time.AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(s.c.Hostname)...)
+		buf = append(buf, ' ')
+		buf = append(buf, nilValue(s.c.AppName)...)
+		buf = append(buf, ' ')
+		buf = append(buf, s.pid...)
+		buf = append(buf, " - - "...)
+	}
+	// Escaping keeps messages from forging entries, which over unix
+	// sockets are delimited by newlines.
+	buf = appendEscaped(buf, e.Message)
+	return AppendFields(buf, e.Fields)
+}
+
+// WriteEntry implements Sink.
+func (s *Syslog) WriteEntry(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.buf = s.format(s.buf[0:0], e)
+	msg := s.buf
+	switch s.c.Network {
+	case "tcp", "tcp4", "tcp6":
+		msg = append(strconv.AppendInt(nil, int64(len(s.buf)), 10), ' ')
+		msg = append(msg, s.buf...)
+	case "unix":
+		s.buf = append(s.buf, '\n')
+		msg = s.buf
+	}
+	if s.conn == nil {
+		if err := s.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.conn.Write(msg); err != nil {
+		// The daemon may have restarted; try once more.
+		s.conn.Close()
+		s.conn = nil
+		if err := s.dial(); err != nil {
+			return err
+		}
+		_, err = s.conn.Write(msg)
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,103 @@
+package log
+
+import "strings"
+
+// expand fills the {name} placeholders of tmpl with the values of the
+// fields named name. Placeholders without a field are left alone and {{
+// stands for a literal brace.
+func expand(tmpl string, fields []Field) string {
+	if strings.IndexByte(tmpl, '{') < 0 {
+		return tmpl
+	}
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(tmpl, '{')
+		if i < 0 {
+			break
+		}
+		b.WriteString(tmpl[:i])
+		tmpl = tmpl[i:]
+		if strings.HasPrefix(tmpl, "{{") {
+			b.WriteByte('{')
+			tmpl = tmpl[2:]
+			continue
+		}
+		j := strings.IndexByte(tmpl, '}')
+		if j < 0 {
+			break
+		}
+		name := tmpl[1:j]
+		found := false
+		for _, f := range fields {
+			if f.Key == name {
+				b.WriteString(fieldString(f.Value))
+				found = true
+				break
+			}
+		}
+		if !found {
+			b.WriteString(tmpl[:j+1])
+		}
+		tmpl = tmpl[j+1:]
+	}
+	b.WriteString(tmpl)
+	return b.String()
+}
+
+// LogT outputs a message built from a template with named placeholders,
+// such as "user {user} logged in from {ip}", filled in from the fields.
+// The fields are attached to the entry as usual, along with the template
+// itself under the key template, so entries can be grouped by it.
+func (log *Logger) LogT(l Level, tmpl string, fields ...Field) {
+	log.outputT(l, tmpl, fields)
+}
+
+// TraceT is LogT at the trace log level.
+func (log *Logger) TraceT(tmpl string, fields ...Field) {
+	log.outputT(LevelTrace, tmpl, fields)
+}
+
+// DebugT is LogT at the debug log level.
+func (log *Logger) DebugT(tmpl string, fields ...Field) {
+	log.outputT(LevelDebug, tmpl, fields)
+}
+
+// InfoT is LogT at the info log level.
+func (log *Logger) InfoT(tmpl string, fields ...Field) {
+	log.outputT(LevelInfo, tmpl, fields)
+}
+
+// WarnT is LogT at the warn log level.
+func (log *Logger) WarnT(tmpl string, fields ...Field) {
+	log.outputT(LevelWarn, tmpl, fields)
+}
+
+// ErrorT is LogT at the error log level.
+func (log *Logger) ErrorT(tmpl string, fields ...Field) {
+	log.outputT(LevelError, tmpl, fields)
+}
+
+func (log *Logger) outputT(l Level, tmpl string, given []Field) {
+	if log.discards(l) {
+		return
+	}
+	// A copy with room for the template, so that the fields of the
+	// caller do not escape and cost nothing if the entry is discarded.
+	fields := resolveLazy(append(make([]Field, 0, len(given)+1), given...))
+	log.Lock()
+	bound, group := log.fields, log.group
+	log.Unlock()
+	if group != "" {
+		fields = groupFields(group, fields)
+	}
+	r := log.root
+	r.Lock()
+	rules := r.redact
+	r.Unlock()
+	// Placeholders name fields as they were given, without the group of
+	// the logger, but redaction sees the keys as they are written.
+	all := redactFields(rules, append(bound[:len(bound):len(bound)], fields...))
+	msg := expand(tmpl, ungroupFields(group, all))
+	fields = append(fields, Field{Key: "template", Value: tmpl})
+	log.output(3, l, tmpl, msg, fields)
+}
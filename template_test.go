@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTemplateGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		logger func(l *Logger) *Logger
+		tmpl   string
+		fields []Field
+		want   string
+	}{
+		{"no group", func(l *Logger) *Logger { return l },
+			"user {user} in", []Field{Str("user", "bob")},
+			"user bob in user=bob template=\"user {user} in\""},
+		{"call field", func(l *Logger) *Logger { return l.WithGroup("http") },
+			"user {user} in", []Field{Str("user", "bob")},
+			"user bob in http.user=bob template=\"user {user} in\""},
+		{"bound field", func(l *Logger) *Logger { return l.WithGroup("http").With("user", "bob") },
+			"user {user} in", nil,
+			"user bob in http.user=bob template=\"user {user} in\""},
+		{"bound before group", func(l *Logger) *Logger { return l.With("user", "bob").WithGroup("http") },
+			"user {user} in", nil,
+			"user bob in user=bob template=\"user {user} in\""},
+		{"nested groups", func(l *Logger) *Logger { return l.WithGroup("a").WithGroup("b") },
+			"{id}", []Field{Int("id", 7)},
+			"7 a.b.id=7 template={id}"},
+		{"redacted", func(l *Logger) *Logger { return l.WithGroup("http") },
+			"token {token}", []Field{Str("token", "s3cret")},
+			"token [REDACTED] http.token=[REDACTED] template=\"token {token}\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf, LevelInfo, FlagNoDate|FlagNoTime, nil)
+			l.SetRedaction(RedactRule{Key: regexp.MustCompile(`(^|\.)token$`)})
+			tt.logger(l).InfoT(tt.tmpl, tt.fields...)
+			got := strings.TrimSpace(strings.TrimPrefix(buf.String(), "INFO"))
+			if got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
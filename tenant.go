@@ -0,0 +1,67 @@
+package log
+
+import "sync"
+
+// Tenants hands out loggers for the tenants of a multi-tenant service.
+// Every tenant logger writes through the shared parent but has its own
+// rate limit, shared with the loggers derived from it, and minimum level,
+// so one noisy tenant cannot flood the output for everyone else.
+type Tenants struct {
+	mu     sync.Mutex
+	parent *Logger
+	rate   float64
+	burst  int
+	m      map[string]*Logger
+}
+
+// NewTenants creates a tenant registry deriving loggers from parent.
+// Each tenant may log rate entries per second with bursts of up to burst
+// entries; a rate of zero disables limiting.
+func NewTenants(parent *Logger, rate float64, burst int) *Tenants {
+	return &Tenants{parent: parent, rate: rate, burst: burst, m: make(map[string]*Logger)}
+}
+
+// Logger returns the logger for tenant id, creating it if necessary.
+// Messages are prefixed with the tenant id in brackets.
+func (t *Tenants) Logger(id string) *Logger {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	log, ok := t.m[id]
+	if !ok {
+		log = t.parent.derive()
+		log.prefix += "[" + id + "] "
+		log.lim = newLimiter(t.rate, t.burst)
+		t.m[id] = log
+	}
+	return log
+}
+
+// SetLevel overrides the minimum level of tenant id.
+func (t *Tenants) SetLevel(id string, l Level) {
+	log := t.Logger(id)
+	log.Lock()
+	log.min = l
+	log.local |= localMin
+	log.Unlock()
+}
+
+// SetLimit overrides the rate limit of tenant id. Loggers derived from
+// the tenant's logger before keep the limit they share.
+func (t *Tenants) SetLimit(id string, rate float64, burst int) {
+	log := t.Logger(id)
+	log.Lock()
+	log.lim = newLimiter(rate, burst)
+	log.Unlock()
+}
+
+// Dropped returns the number of entries of tenant id, and of the loggers
+// derived from its logger, discarded by its rate limit.
+func (t *Tenants) Dropped(id string) uint64 {
+	log := t.Logger(id)
+	log.Lock()
+	defer log.Unlock()
+	if log.lim == nil {
+		return 0
+	}
+	return log.lim.droppedCount()
+}
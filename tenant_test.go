@@ -0,0 +1,40 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTenantLimitCoversDerived(t *testing.T) {
+	tests := []struct {
+		name   string
+		derive func(l *Logger) *Logger
+	}{
+		{"tenant", func(l *Logger) *Logger { return l }},
+		{"With", func(l *Logger) *Logger { return l.With("k", 1) }},
+		{"Named", func(l *Logger) *Logger { return l.Named("db") }},
+		{"WithPrefix", func(l *Logger) *Logger { return l.WithPrefix("p: ") }},
+		{"WithGroup", func(l *Logger) *Logger { return l.WithGroup("g") }},
+		{"WithMinLevel", func(l *Logger) *Logger { return l.WithMinLevel(LevelDebug) }},
+		{"nested", func(l *Logger) *Logger { return l.Named("a").With("k", 1).WithGroup("g") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tenants := NewTenants(New(&buf, LevelInfo, FlagNoDate, nil), 1e-9, 1)
+			tl := tenants.Logger("acme")
+			child := tt.derive(tl)
+			for i := 0; i < 5; i++ {
+				child.Info("child")
+				tl.Info("tenant")
+			}
+			if n := strings.Count(buf.String(), "\n"); n != 1 {
+				t.Errorf("%d lines written, want 1:\n%s", n, buf.String())
+			}
+			if n := tenants.Dropped("acme"); n != 9 {
+				t.Errorf("Dropped = %d, want 9", n)
+			}
+		})
+	}
+}
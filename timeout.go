@@ -0,0 +1,97 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrWriteTimeout is returned by a TimeoutWriter when a write does not
+// complete in time.
+var ErrWriteTimeout = errors.New("log: write timed out")
+
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+type writeResult struct {
+	n   int
+	err error
+}
+
+// A TimeoutWriter bounds the time spent writing to a network connection or
+// pipe, so a hung reader cannot stall every goroutine that logs.
+// Writers supporting write deadlines, such as net.Conn, get a deadline set
+// before each write. For other writers the write runs in a separate
+// goroutine that is abandoned when it takes too long; until it returns,
+// further writes fail immediately with ErrWriteTimeout.
+type TimeoutWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	d       time.Duration
+	pending bool
+}
+
+// NewTimeoutWriter creates a writer giving each write to w at most d.
+func NewTimeoutWriter(w io.Writer, d time.Duration) *TimeoutWriter {
+	return &TimeoutWriter{w: w, d: d}
+}
+
+// Reopen reopens the underlying writer, if it is a File or can be
+// reopened otherwise.
+func (w *TimeoutWriter) Reopen() error {
+	if ro, ok := w.w.(reopener); ok {
+		return ro.Reopen()
+	}
+	return nil
+}
+
+// Write writes p to the underlying writer. A write failing after its
+// time is up returns ErrWriteTimeout, whatever the underlying error.
+func (w *TimeoutWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending {
+		return 0, ErrWriteTimeout
+	}
+	deadline := time.Now().Add(w.d)
+	if dl, ok := w.w.(writeDeadliner); ok {
+		if dl.SetWriteDeadline(deadline) == nil {
+			n, err := w.w.Write(p)
+			return n, timedOut(err, deadline)
+		}
+	}
+	// The write may outlive this call, so it gets its own copy of p.
+	b := append([]byte(nil), p...)
+	c := make(chan writeResult, 1)
+	go func() {
+		n, err := w.w.Write(b)
+		c <- writeResult{n, err}
+	}()
+	t := time.NewTimer(w.d)
+	defer t.Stop()
+	select {
+	case r := <-c:
+		return r.n, timedOut(r.err, deadline)
+	case <-t.C:
+		w.pending = true
+		go func() {
+			<-c
+			w.mu.Lock()
+			w.pending = false
+			w.mu.Unlock()
+		}()
+		return 0, ErrWriteTimeout
+	}
+}
+
+// timedOut returns ErrWriteTimeout in place of err if the write failed
+// by or after deadline.
+func timedOut(err error, deadline time.Time) error {
+	if err != nil && (errors.Is(err, os.ErrDeadlineExceeded) || !time.Now().Before(deadline)) {
+		return ErrWriteTimeout
+	}
+	return err
+}
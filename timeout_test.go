@@ -0,0 +1,50 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// slowWriter fails every write with err after delay.
+type slowWriter struct {
+	delay time.Duration
+	err   error
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	if w.err != nil {
+		return 0, w.err
+	}
+	return len(p), nil
+}
+
+func TestTimeoutWriter(t *testing.T) {
+	broken := errors.New("broken pipe")
+	tests := []struct {
+		name    string
+		w       func(t *testing.T) io.Writer
+		wantErr error
+	}{
+		{"in time", func(*testing.T) io.Writer { return &slowWriter{} }, nil},
+		{"error in time", func(*testing.T) io.Writer { return &slowWriter{err: broken} }, broken},
+		{"hung", func(*testing.T) io.Writer { return &slowWriter{delay: time.Second} }, ErrWriteTimeout},
+		{"deadline", func(t *testing.T) io.Writer {
+			// Nobody reads the other end of the pipe.
+			c, other := net.Pipe()
+			t.Cleanup(func() { c.Close(); other.Close() })
+			return c
+		}, ErrWriteTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := NewTimeoutWriter(tt.w(t), 50*time.Millisecond)
+			if _, err := tw.Write([]byte("entry\n")); err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
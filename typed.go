@@ -0,0 +1,100 @@
+package log
+
+import (
+	"math"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	kindNone fieldKind = iota
+	kindString
+	kindInt
+	kindInt64
+	kindFloat64
+	kindBool
+	kindDuration
+)
+
+// Str returns a field holding the string s. Like the other typed
+// constructors, it defers converting the value to an interface until the
+// entry is written, so fields of discarded entries cost no allocations.
+func Str(key, s string) Field {
+	return Field{Key: key, kind: kindString, str: s}
+}
+
+// Int returns a field holding the int n.
+func Int(key string, n int) Field {
+	return Field{Key: key, kind: kindInt, num: uint64(n)}
+}
+
+// Int64 returns a field holding the int64 n.
+func Int64(key string, n int64) Field {
+	return Field{Key: key, kind: kindInt64, num: uint64(n)}
+}
+
+// Float64 returns a field holding the float64 f.
+func Float64(key string, f float64) Field {
+	return Field{Key: key, kind: kindFloat64, num: math.Float64bits(f)}
+}
+
+// Bool returns a field holding the bool b.
+func Bool(key string, b bool) Field {
+	var n uint64
+	if b {
+		n = 1
+	}
+	return Field{Key: key, kind: kindBool, num: n}
+}
+
+// Dur returns a field holding the duration d.
+func Dur(key string, d time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, num: uint64(d)}
+}
+
+// Time returns a field holding the time t.
+func Time(key string, t time.Time) Field {
+	return Field{Key: key, Value: t}
+}
+
+// Any returns a field holding v, as F does.
+func Any(key string, v interface{}) Field {
+	return Field{Key: key, Value: v}
+}
+
+// box returns the value of f as an interface.
+func (f *Field) box() interface{} {
+	switch f.kind {
+	case kindString:
+		return f.str
+	case kindInt:
+		return int(f.num)
+	case kindInt64:
+		return int64(f.num)
+	case kindFloat64:
+		return math.Float64frombits(f.num)
+	case kindBool:
+		return f.num != 0
+	case kindDuration:
+		return time.Duration(f.num)
+	}
+	return f.Value
+}
+
+// boxFields returns fields with the values of typed fields set, copying
+// them if any is typed.
+func boxFields(fields []Field) []Field {
+	copied := false
+	for i := range fields {
+		if fields[i].kind == kindNone {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = Field{Key: fields[i].Key, Value: fields[i].box()}
+	}
+	return fields
+}